@@ -5,7 +5,6 @@ import (
 	"crypto/md5"
 	"encoding/json"
 	"fmt"
-	"github.com/tidwall/gjson"
 	"io"
 	"net/http"
 	"net/url"
@@ -21,22 +20,99 @@ type AppRestConfig struct {
 	AppSecret   string
 	AppUrl      string
 	EventCreate func(ctx context.Context) RestEvent
+	MaxBodySize int64                       //返回内容最大字节数,默认0不限制
+	Builds      map[string]*RestBuildConfig //按接口KEY覆盖接口配置,可以为nil
+	//SecretProvider 签名时获取密钥,设置后忽略 AppSecret
+	SecretProvider RestSecretProvider
+	//Envelope 返回内容外层结构规则,默认 AppRestEnvelope
+	Envelope *RestEnvelope
 }
 
 func (clf *AppRestConfig) GetName() string {
 	return clf.Name
 }
 
+// BaseUrl 服务地址
+func (clf *AppRestConfig) BaseUrl() string {
+	return clf.AppUrl
+}
+
+// GetMaxBodySize 返回内容最大字节数
+func (clf *AppRestConfig) GetMaxBodySize() int64 {
+	return clf.MaxBodySize
+}
+
+// GetBuilds 按接口KEY的覆盖配置
+func (clf *AppRestConfig) GetBuilds() map[string]*RestBuildConfig {
+	return clf.Builds
+}
+
+func (clf *AppRestConfig) String() string {
+	return fmt.Sprintf("AppRestConfig{Name:%s AppKey:%s AppSecret:****** AppUrl:%s}", clf.Name, clf.AppKey, clf.AppUrl)
+}
+
+func (clf *AppRestConfig) GoString() string {
+	return clf.String()
+}
+
+// Secret 签名使用的密钥
+func (clf *AppRestConfig) Secret(ctx context.Context) (*RestSecret, error) {
+	if clf.SecretProvider != nil {
+		return clf.SecretProvider.Secret(ctx, clf.Name)
+	}
+	return &RestSecret{Current: clf.AppSecret}, nil
+}
+
+// Valid 校验配置
+func (clf *AppRestConfig) Valid() error {
+	if len(clf.Name) == 0 {
+		return NewRestConfigError("name", "config name is empty")
+	}
+	if len(clf.AppKey) == 0 {
+		return NewRestConfigError(clf.Name+".app_key", "app key is empty")
+	}
+	if _, err := url.ParseRequestURI(clf.AppUrl); err != nil {
+		return NewRestConfigError(clf.Name+".app_url", "app url is wrong:"+clf.AppUrl)
+	}
+	return nil
+}
+
+// NewEvent 创建请求事件,未配置 EventCreate 时返回默认事件
+func (clf *AppRestConfig) NewEvent(ctx context.Context) RestEvent {
+	if clf.EventCreate != nil {
+		return clf.EventCreate(ctx)
+	}
+	return &RestEventNoop{}
+}
+
 type AppClientError struct {
-	Msg     string
-	Code    string
-	SubCode string
+	Msg       string
+	Code      string
+	SubCode   string
+	ServerMsg string         //服务返回的原始错误信息
+	Define    *RestCodeError //注册的错误码定义,未注册时为nil
 }
 
 func (err *AppClientError) Error() string {
 	return fmt.Sprintf("%s [%s]", err.Msg, err.Code)
 }
 
+// Unwrap 返回注册的错误码定义,可通过 errors.Is 匹配错误码及分类
+func (err *AppClientError) Unwrap() error {
+	if err.Define == nil {
+		return nil
+	}
+	return err.Define
+}
+
+// UserMessage 面向用户的错误信息,未注册错误码或未设置模板时返回空字符串
+func (err *AppClientError) UserMessage() string {
+	if err.Define == nil {
+		return ""
+	}
+	return strings.NewReplacer("{code}", err.Code, "{sub_code}", err.SubCode, "{message}", err.ServerMsg).Replace(err.Define.Message)
+}
+
 // NewAppClientError  错误创建
 func NewAppClientError(code string, subCode string, msg string) *AppClientError {
 	return &AppClientError{
@@ -52,38 +128,88 @@ type AppRestBuild struct {
 	Path       string        //接口路径
 	HttpMethod string
 	Method     string
+	Hedge      *RestHedge  //对冲请求配置,仅对GET请求生效,默认不启用
+	Decode     *RestDecode //返回内容解码配置,默认按返回HEADER解压及转码
+	//MaxBodySize 返回内容最大字节数,默认0使用服务配置
+	MaxBodySize int64
+	//Envelope 返回内容外层结构规则,默认使用服务配置
+	Envelope *RestEnvelope
 }
 
+// AppRestEventMaxBuffer AppRestEvent 默认最多缓存的请求及返回内容字节数
+const AppRestEventMaxBuffer = 1 << 20
+
 func NewAppRestEvent(logger func(method string, url string, httpCode int, httpHeader map[string][]string, request []byte, response []byte, err error)) *AppRestEvent {
 	return &AppRestEvent{
-		logger: logger,
+		logger:    logger,
+		maxBuffer: AppRestEventMaxBuffer,
+	}
+}
+
+// NewAppRestKeyEvent 创建带接口KEY的事件,日志中可区分请求的接口
+func NewAppRestKeyEvent(logger func(key string, method string, url string, httpCode int, httpHeader map[string][]string, request []byte, response []byte, err error)) *AppRestEvent {
+	event := &AppRestEvent{maxBuffer: AppRestEventMaxBuffer}
+	event.logger = func(method string, url string, httpCode int, httpHeader map[string][]string, request []byte, response []byte, err error) {
+		logger(event.key, method, url, httpCode, httpHeader, request, response, err)
 	}
+	return event
 }
 
 // AppRestEvent 接口事件实现
 type AppRestEvent struct {
+	key        string
 	method     string
 	url        string
 	httpCode   int
 	httpHeader map[string][]string
 	request    []byte
 	response   []byte
+	maxBuffer  int
+	truncated  bool
 	logger     func(method string, url string, httpCode int, httpHeader map[string][]string, request []byte, response []byte, err error)
 }
 
+func (event *AppRestEvent) RequestKey(key RestKey) {
+	event.key = key.String()
+}
 func (event *AppRestEvent) RequestStart(method, url string) {
 	event.method = method
 	event.url = url
 }
 func (event *AppRestEvent) RequestRead(data []byte) {
-	event.request = append(event.request, data...)
+	event.request = event.buffer(event.request, data)
 }
 func (event *AppRestEvent) ResponseHeader(httpCode int, httpHeader map[string][]string) {
 	event.httpCode = httpCode
 	event.httpHeader = httpHeader
 }
 func (event *AppRestEvent) ResponseRead(data []byte) {
-	event.response = append(event.response, data...)
+	event.response = event.buffer(event.response, data)
+}
+
+// WithMaxBuffer 设置最多缓存的请求及返回内容字节数,小于等于0时不限制
+func (event *AppRestEvent) WithMaxBuffer(size int) *AppRestEvent {
+	event.maxBuffer = size
+	return event
+}
+
+// Truncated 缓存的请求或返回内容是否被截断
+func (event *AppRestEvent) Truncated() bool {
+	return event.truncated
+}
+
+// buffer 追加缓存内容,超过最大字节数时截断
+func (event *AppRestEvent) buffer(buf []byte, data []byte) []byte {
+	if event.maxBuffer > 0 && len(buf)+len(data) > event.maxBuffer {
+		event.truncated = true
+		//最大字节数可能在写入之间被调小,已缓存内容超出时不再追加
+		remain := event.maxBuffer - len(buf)
+		if remain < 0 {
+			remain = 0
+		}
+		return append(buf, data[:remain]...)
+	}
+	return append(buf, data...)
 }
 func (event *AppRestEvent) ResponseFinish(err error) {
 	if event.logger != nil {
@@ -126,8 +252,28 @@ func AppRestParamSign(version, appKey, method, timestamp, content, appSecret str
 	return fmt.Sprintf("%x", dataSign)
 }
 
+// BuildInfo 接口信息
+func (clt *AppRestBuild) BuildInfo() *RestEndpoint {
+	return &RestEndpoint{
+		HttpMethod: clt.HttpMethod,
+		Path:       clt.Path,
+		Method:     clt.Method,
+		Timeout:    clt.Timeout,
+	}
+}
+
+// ResponseDecode 返回内容解码配置
+func (clt *AppRestBuild) ResponseDecode() *RestDecode {
+	return clt.Decode
+}
+
 // BuildRequest 执行请求
-func (clt *AppRestBuild) BuildRequest(ctx context.Context, client *RestClient, _ int, param interface{}, _ *RestCallerInfo) *RestResult {
+func (clt *AppRestBuild) BuildRequest(ctx context.Context, client *RestClient, key int, param interface{}, callerInfo *RestCallerInfo) *RestResult {
+	return clt.BuildKeyRequest(ctx, client, restIntKey(key), param, callerInfo)
+}
+
+// BuildKeyRequest 按接口KEY执行请求
+func (clt *AppRestBuild) BuildKeyRequest(ctx context.Context, client *RestClient, key RestKey, param interface{}, _ *RestCallerInfo) *RestResult {
 	tConfig, err := client.GetConfig(ctx)
 	if err != nil {
 		return NewRestResultFromError(err, &RestEventNoop{})
@@ -137,18 +283,20 @@ func (clt *AppRestBuild) BuildRequest(ctx context.Context, client *RestClient, _
 		return NewRestResultFromError(NewRestClientError("11", "build config is wrong"), &RestEventNoop{})
 	}
 
-	var event RestEvent
-	if config.EventCreate != nil {
-		event = config.EventCreate(ctx)
-	} else {
-		event = &RestEventNoop{}
-	}
+	event := restBuildEvent(ctx, config, key)
 
-	transport := client.GetTransport()
-	headerTime := transport.ResponseHeaderTimeout
+	transport := client.GetConfigTransport(config.GetName())
 	apiUrl := config.AppUrl
 	appid := config.AppKey
-	keyConfig := config.AppSecret
+	secret, err := config.Secret(ctx)
+	if err != nil {
+		return NewRestResultFromError(err, event)
+	}
+	keyConfig := secret.Active(time.Now())
+	var redact []string
+	for _, val := range secret.values() {
+		redact = append(redact, val, url.QueryEscape(val))
+	}
 
 	jsonParam, err := json.Marshal(param)
 	if err != nil {
@@ -185,21 +333,22 @@ func (clt *AppRestBuild) BuildRequest(ctx context.Context, client *RestClient, _
 		pData.Set(key, val)
 	}
 	paramStr := pData.Encode()
-	apiUrl += clt.Path
 	var ioRead io.Reader
 	if clt.HttpMethod == http.MethodGet {
-		if strings.Index(apiUrl, "?") == -1 {
-			apiUrl += "?" + paramStr
-		} else {
-			apiUrl += "&" + paramStr
-		}
+		apiUrl = appendUrlQuery(apiUrl+clt.Path, paramStr)
 		ioRead = nil
 	} else {
-		ioRead = NewRestRequestReader(strings.NewReader(paramStr), event)
+		apiUrl += clt.Path
+		ioRead = NewRestRequestReader(strings.NewReader(paramStr), event).WithRedact(redact...)
 	}
-	event.RequestStart(clt.HttpMethod, apiUrl)
+	event.RequestStart(clt.HttpMethod, string(restSecretRedact([]byte(apiUrl), redact)))
+	send := newRestTimeoutSend(ctx)
 	var req *http.Request
-	req, err = http.NewRequest(clt.HttpMethod, apiUrl, ioRead)
+	req, err = http.NewRequestWithContext(send.ctx, clt.HttpMethod, apiUrl, ioRead)
+	if err != nil {
+		send.cancel()
+		return NewRestResultFromError(err, event)
+	}
 
 	if rid, find := client.Api.(AppRestRequestId); find {
 		tmp := rid.RequestId(ctx)
@@ -209,36 +358,127 @@ func (clt *AppRestBuild) BuildRequest(ctx context.Context, client *RestClient, _
 	if clt.HttpMethod == http.MethodPost {
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	}
-	if err != nil {
+
+	timeout, maxBodySize := restBuildLimit(config, key, clt.Timeout, clt.MaxBodySize)
+	send.start(timeout)
+	httpClient := &http.Client{
+		Transport: client.headerTransport(transport, timeout),
+	}
+	var res *http.Response
+	if clt.Hedge != nil && clt.HttpMethod == http.MethodGet {
+		var hedgeUrls []string
+		for _, hedgeUrl := range clt.Hedge.Urls {
+			hedgeUrls = append(hedgeUrls, appendUrlQuery(hedgeUrl+clt.Path, paramStr))
+		}
+		res, err = clt.Hedge.Do(send.ctx, httpClient, req, hedgeUrls, event)
+	} else {
+		res, err = httpClient.Do(req)
+	}
+	if res, err = send.finish(res, err); err != nil {
 		return NewRestResultFromError(err, event)
 	}
+	return NewRestResult(clt, res, event).WithMaxBodySize(maxBodySize).WithEnvelope(clt.envelope(config)).
+		WithErrorCodes(client.manager.ErrorCodes(config.Name))
+}
 
-	if clt.Timeout > 0 {
-		transport.ResponseHeaderTimeout = clt.Timeout
+// envelope 返回内容外层结构规则,优先使用接口配置,其次服务配置
+func (clt *AppRestBuild) envelope(config *AppRestConfig) *RestEnvelope {
+	if clt.Envelope != nil {
+		return clt.Envelope
 	}
-	httpClient := &http.Client{
-		Transport: transport,
+	if config != nil && config.Envelope != nil {
+		return config.Envelope
 	}
-	res, err := httpClient.Do(req)
-	if clt.Timeout > 0 {
-		transport.ResponseHeaderTimeout = headerTime
+	return AppRestEnvelope
+}
+
+// restTimeoutSend 请求上下文,等待HEADER超时通过取消请求实现,不修改公共的Transport
+type restTimeoutSend struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	timer  *time.Timer
+}
+
+func newRestTimeoutSend(ctx context.Context) *restTimeoutSend {
+	send := &restTimeoutSend{}
+	send.ctx, send.cancel = context.WithCancel(ctx)
+	return send
+}
+
+// start 开始计时,timeout 小于等于0时不限制
+func (send *restTimeoutSend) start(timeout time.Duration) {
+	if timeout > 0 {
+		send.timer = time.AfterFunc(timeout, send.cancel)
+	}
+}
+
+// finish 停止计时,成功时返回内容关闭后结束请求上下文
+func (send *restTimeoutSend) finish(res *http.Response, err error) (*http.Response, error) {
+	if send.timer != nil && !send.timer.Stop() {
+		if err == nil {
+			_ = res.Body.Close()
+		}
+		err = NewRestClientError("13", "timeout awaiting response headers")
 	}
 	if err != nil {
-		return NewRestResultFromError(err, event)
-	} else {
-		return NewRestResult(clt, res, event)
+		send.cancel()
+		return nil, err
 	}
+	res.Body = &restCancelBody{ReadCloser: res.Body, cancel: send.cancel}
+	return res, nil
 }
 
-func (clt *AppRestBuild) CheckJsonResult(body string) error {
-	code := gjson.Get(body, "result.code").String()
-	state := gjson.Get(body, "result.state").String()
-	if code != "200" || state != "ok" {
-		msg := gjson.Get(body, "result.message").String()
-		if len(msg) == 0 {
-			msg = body
+// headerTransport 接口超时大于 Transport 的等待HEADER超时时,返回不限制等待HEADER的副本,由 restTimeoutSend 计时
+// 副本按 Transport 缓存以复用连接, Transport 不再使用时关闭
+func (client *RestClient) headerTransport(transport *http.Transport, timeout time.Duration) *http.Transport {
+	if timeout <= 0 || transport.ResponseHeaderTimeout <= 0 || transport.ResponseHeaderTimeout >= timeout {
+		return transport
+	}
+	if tmp, ok := client.manager.headerTransports.Load(transport); ok {
+		return tmp.(*http.Transport)
+	}
+	clone := transport.Clone()
+	clone.ResponseHeaderTimeout = 0
+	tmp, loaded := client.manager.headerTransports.LoadOrStore(transport, clone)
+	if loaded {
+		clone.CloseIdleConnections()
+	}
+	return tmp.(*http.Transport)
+}
+
+// closeHeaderTransports 关闭已不再使用的 Transport 的副本
+func (c *RestClientManager) closeHeaderTransports(snapshot *restConfigSnapshot) {
+	used := snapshot.transports()
+	c.headerTransports.Range(func(key, value interface{}) bool {
+		if !used[key.(*http.Transport)] {
+			c.headerTransports.Delete(key)
+			value.(*http.Transport).CloseIdleConnections()
 		}
-		return NewAppClientError(code, gjson.Get(body, "result.state").String(), "server return fail:"+msg)
+		return true
+	})
+}
+
+// restCancelBody 关闭返回内容时结束请求上下文
+type restCancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (body *restCancelBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+	return err
+}
+
+// appendUrlQuery URL追加查询参数
+func appendUrlQuery(apiUrl string, query string) string {
+	if strings.Index(apiUrl, "?") == -1 {
+		return apiUrl + "?" + query
 	}
-	return nil
+	return apiUrl + "&" + query
+}
+
+// CheckJsonResult 按接口配置的 Envelope 检测返回内容,未配置时使用 AppRestEnvelope
+func (clt *AppRestBuild) CheckJsonResult(body string) error {
+	return clt.envelope(nil).CheckJsonResult(body)
 }
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// exportName 转为导出的Go名称,如 product_detail => ProductDetail
+func exportName(name string) string {
+	var buf strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			buf.WriteRune(unicode.ToUpper(r))
+			upper = false
+		} else {
+			buf.WriteRune(r)
+		}
+	}
+	return buf.String()
+}
+
+// fieldTag 生成字段标签
+func fieldTag(field *Field) string {
+	tag := `json:"` + field.Name + `,omitempty"`
+	if len(field.Validate) > 0 {
+		tag += ` validate:"` + field.Validate + `"`
+	}
+	return "`" + tag + "`"
+}
+
+func httpMethod(method string) string {
+	switch method {
+	case "GET":
+		return "http.MethodGet"
+	case "POST":
+		return "http.MethodPost"
+	case "PUT":
+		return "http.MethodPut"
+	case "DELETE":
+		return "http.MethodDelete"
+	case "PATCH":
+		return "http.MethodPatch"
+	}
+	return `"` + method + `"`
+}
+
+// durationExpr 时间转为Go表达式,如 3 * time.Second
+func durationExpr(duration time.Duration) string {
+	switch {
+	case duration%time.Second == 0:
+		return fmt.Sprintf("%d * time.Second", duration/time.Second)
+	case duration%time.Millisecond == 0:
+		return fmt.Sprintf("%d * time.Millisecond", duration/time.Millisecond)
+	}
+	return fmt.Sprintf("time.Duration(%d)", duration)
+}
+
+var genTemplate = template.Must(template.New("rest_gen").Funcs(template.FuncMap{
+	"export":     exportName,
+	"tag":        fieldTag,
+	"httpMethod": httpMethod,
+	"duration":   durationExpr,
+}).Parse(`// Code generated by rest_gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"github.com/hsbteam/rest_client"
+	"net/http"
+	"reflect"
+	"strconv"
+{{- if .HasTimeout}}
+	"time"
+{{- end}}
+)
+
+//{{.Service}}Key {{.Service}} 的接口KEY
+type {{.Service}}Key int
+
+// 一个接口一个常量
+const (
+{{- range $i, $build := .Builds}}
+	{{$build.Name}} {{$.Service}}Key = iota
+{{- end}}
+)
+
+func (key {{.Service}}Key) String() string {
+	switch key {
+{{- range .Builds}}
+	case {{.Name}}:
+		return "{{.Name}}"
+{{- end}}
+	}
+	return "{{.Service}}Key(" + strconv.Itoa(int(key)) + ")"
+}
+{{if not .External}}
+//{{.Service}} 一个服务一个结构
+type {{.Service}} struct{}
+{{end}}
+func (res *{{.Service}}) ConfigBuilds(_ context.Context) (map[int]rest_client.RestBuild, error) {
+	return map[int]rest_client.RestBuild{
+{{- range .Builds}}
+		int({{.Name}}): &rest_client.AppRestBuild{
+			HttpMethod: {{httpMethod .HttpMethod}},
+			Path:       "{{.Path}}",
+			Method:     "{{.Method}}",
+{{- if gt .Timeout 0}}
+			Timeout:    {{duration .Timeout}},
+{{- end}}
+		},
+{{- end}}
+	}, nil
+}
+
+//ConfigName 配置名称
+func (res *{{.Service}}) ConfigName(_ context.Context) (string, error) {
+	return "{{.Config}}", nil
+}
+
+//KeyType 只接受 {{.Service}}Key 类型的KEY
+func (res *{{.Service}}) KeyType() reflect.Type {
+	return reflect.TypeOf({{.Service}}Key(0))
+}
+{{range .Builds}}
+//{{.Name}}Request {{.Method}} 接口请求参数
+type {{.Name}}Request struct {
+{{- range .Request}}
+	{{export .Name}} {{.Type}} {{tag .}}{{if .Comment}} //{{.Comment}}{{end}}
+{{- end}}
+}
+
+//{{.Name}}Response {{.Method}} 接口返回数据
+type {{.Name}}Response struct {
+{{- range .Response}}
+	{{export .Name}} {{.Type}} {{tag .}}{{if .Comment}} //{{.Comment}}{{end}}
+{{- end}}
+}
+{{end}}
+var {{.Service}}Valid = validator.New()
+
+//{{.Service}}Client {{.Service}} 的类型化调用
+type {{.Service}}Client struct {
+	*rest_client.RestClient
+}
+
+//New{{.Service}}Client 创建类型化调用
+func New{{.Service}}Client(manager *rest_client.RestClientManager, api *{{.Service}}) *{{.Service}}Client {
+	return &{{.Service}}Client{manager.NewApi(api)}
+}
+{{range .Builds}}
+//{{.Name}} 调用 {{.Method}} 接口
+func (client *{{$.Service}}Client) {{.Name}}(ctx context.Context, req *{{.Name}}Request) (*{{.Name}}Response, error) {
+	if err := {{$.Service}}Valid.StructCtx(ctx, req); err != nil {
+		return nil, err
+	}
+	data := (<-client.Do(ctx, {{.Name}}, req)).JsonResult()
+	if err := data.Err(); err != nil {
+		return nil, err
+	}
+	res := &{{.Name}}Response{}
+	if err := data.GetStruct("{{.ResponsePath}}", res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+{{end}}`))
+
+// Generate 生成Go代码
+func Generate(spec *Spec) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := genTemplate.Execute(&buf, spec); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
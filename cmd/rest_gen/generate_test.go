@@ -0,0 +1,147 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSpec = `
+package: product
+service: RestProduct
+config: product
+builds:
+  - name: ProductDetail
+    path: /jp/product
+    method: detail
+    http_method: get
+    timeout: 3s
+    request:
+      - name: id
+        type: string
+        validate: required
+    response:
+      - name: name
+        type: string
+      - name: price
+        type: float64
+        validate: gte=0
+  - name: ProductAdd
+    path: /jp/product
+    method: add
+`
+
+const testOpenapi = `
+openapi: 3.0.0
+x-package: product
+x-service: RestProduct
+x-config: product
+paths:
+  /jp/product:
+    get:
+      operationId: product_detail
+      x-hsb-method: detail
+      x-timeout: 500ms
+      parameters:
+        - name: id
+          in: query
+          required: true
+          schema:
+            type: string
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/Product"
+components:
+  schemas:
+    Product:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        price:
+          type: number
+          minimum: 0
+        tags:
+          type: array
+          items:
+            type: string
+`
+
+// testBuild 编译生成的代码
+func testBuild(t *testing.T, code []byte) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not find")
+	}
+	dir, err := ioutil.TempDir(".", "gen_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "api_gen.go"), code, 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command("go", "build", "./"+filepath.Base(dir)).CombinedOutput()
+	if err != nil {
+		t.Fatal(string(out))
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	spec, err := ParseSpec([]byte(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := Generate(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, find := range []string{
+		"ProductDetail RestProductKey = iota",
+		"int(ProductAdd): &rest_client.AppRestBuild{",
+		"Timeout:    3 * time.Second",
+		`Id string ` + "`" + `json:"id,omitempty" validate:"required"` + "`",
+		"func (client *RestProductClient) ProductAdd(ctx context.Context, req *ProductAddRequest) (*ProductAddResponse, error)",
+	} {
+		if !strings.Contains(string(code), find) {
+			t.Error("generate code not find:" + find)
+		}
+	}
+	testBuild(t, code)
+}
+
+func TestGenerateOpenapi(t *testing.T) {
+	spec, err := ParseSpec([]byte(testOpenapi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.Builds) != 1 || spec.Builds[0].Name != "ProductDetail" || spec.Builds[0].HttpMethod != "GET" {
+		t.Fatal("openapi parse wrong")
+	}
+	code, err := Generate(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, find := range []string{
+		"Timeout:    500 * time.Millisecond",
+		`json:"price,omitempty" validate:"omitempty,gte=0"`,
+		`Tags  []string`,
+	} {
+		if !strings.Contains(string(code), find) {
+			t.Error("generate code not find:" + find)
+		}
+	}
+	testBuild(t, code)
+}
+
+func TestParseSpecError(t *testing.T) {
+	if _, err := ParseSpec([]byte("package: product\nservice: RestProduct\n")); err == nil {
+		t.Error("spec config is empty")
+	}
+}
@@ -0,0 +1,47 @@
+// rest_gen 根据接口描述生成 RestApi 实现,可配合 go generate 使用:
+//
+//	//go:generate go run github.com/hsbteam/rest_client/cmd/rest_gen -in api.yaml -out api_gen.go
+//
+// 接口描述可为简单的YAML或 openapi 文档,openapi 中通过 x-package、x-service、x-config
+// 指定包名、服务结构名及配置名,接口通过 x-hsb-method、x-timeout 指定接口名称及超时时间
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	in := flag.String("in", "", "接口描述文件")
+	out := flag.String("out", "", "生成的Go文件,为空时输出到标准输出")
+	flag.Parse()
+	if len(*in) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	data, err := ioutil.ReadFile(*in)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	spec, err := ParseSpec(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", *in, err)
+		os.Exit(1)
+	}
+	code, err := Generate(spec)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(*out) == 0 {
+		_, _ = os.Stdout.Write(code)
+		return
+	}
+	if err := ioutil.WriteFile(*out, code, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Spec 接口描述
+type Spec struct {
+	Package  string   `yaml:"package"`
+	Service  string   `yaml:"service"`  //服务结构名
+	Config   string   `yaml:"config"`   //配置名
+	External bool     `yaml:"external"` //服务结构由外部定义,不生成结构
+	Builds   []*Build `yaml:"builds"`
+}
+
+// Build 单个接口描述
+type Build struct {
+	Name         string        `yaml:"name"`        //常量及调用方法名
+	Path         string        `yaml:"path"`        //接口路径
+	Method       string        `yaml:"method"`      //hsb接口名称
+	HttpMethod   string        `yaml:"http_method"` //默认POST
+	Timeout      time.Duration `yaml:"timeout"`
+	ResponsePath string        `yaml:"response_path"` //返回数据所在路径,默认data
+	Request      []*Field      `yaml:"request"`
+	Response     []*Field      `yaml:"response"`
+}
+
+// Field 请求或返回字段
+type Field struct {
+	Name     string `yaml:"name"` //JSON字段名
+	Type     string `yaml:"type"` //Go类型
+	Validate string `yaml:"validate"`
+	Comment  string `yaml:"comment"`
+}
+
+// ParseSpec 解析接口描述,内容为 openapi 文档时按 openapi 解析
+func ParseSpec(data []byte) (*Spec, error) {
+	var head struct {
+		Openapi string `yaml:"openapi"`
+	}
+	if err := yaml.Unmarshal(data, &head); err != nil {
+		return nil, err
+	}
+	spec := &Spec{}
+	if len(head.Openapi) > 0 {
+		var doc openapiDoc
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		var err error
+		if spec, err = doc.spec(); err != nil {
+			return nil, err
+		}
+	} else if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, err
+	}
+	return spec, spec.check()
+}
+
+// HasTimeout 是否有接口指定超时时间
+func (spec *Spec) HasTimeout() bool {
+	for _, build := range spec.Builds {
+		if build.Timeout > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (spec *Spec) check() error {
+	if len(spec.Package) == 0 {
+		return fmt.Errorf("spec package is empty")
+	}
+	if len(spec.Service) == 0 {
+		return fmt.Errorf("spec service is empty")
+	}
+	if len(spec.Config) == 0 {
+		return fmt.Errorf("spec config is empty")
+	}
+	names := map[string]bool{}
+	for i, build := range spec.Builds {
+		if len(build.Name) == 0 {
+			return fmt.Errorf("builds[%d] name is empty", i)
+		}
+		if names[build.Name] {
+			return fmt.Errorf("builds[%d] name is repeat:%s", i, build.Name)
+		}
+		names[build.Name] = true
+		if len(build.HttpMethod) == 0 {
+			build.HttpMethod = http.MethodPost
+		}
+		build.HttpMethod = strings.ToUpper(build.HttpMethod)
+		if len(build.ResponsePath) == 0 {
+			build.ResponsePath = "data"
+		}
+		for _, field := range append(build.Request, build.Response...) {
+			if len(field.Name) == 0 {
+				return fmt.Errorf("builds[%d] field name is empty", i)
+			}
+			if len(field.Type) == 0 {
+				field.Type = "string"
+			}
+		}
+	}
+	return nil
+}
+
+/////////////// openapi 部分//////////////////
+
+type openapiSchema struct {
+	Ref         string                    `yaml:"$ref"`
+	Type        string                    `yaml:"type"`
+	Format      string                    `yaml:"format"`
+	Description string                    `yaml:"description"`
+	Properties  map[string]*openapiSchema `yaml:"properties"`
+	Required    []string                  `yaml:"required"`
+	Items       *openapiSchema            `yaml:"items"`
+	Minimum     *float64                  `yaml:"minimum"`
+	Maximum     *float64                  `yaml:"maximum"`
+	MinLength   *int                      `yaml:"minLength"`
+	MaxLength   *int                      `yaml:"maxLength"`
+	Enum        []interface{}             `yaml:"enum"`
+}
+
+type openapiContent map[string]struct {
+	Schema *openapiSchema `yaml:"schema"`
+}
+
+type openapiOperation struct {
+	OperationId string `yaml:"operationId"`
+	Summary     string `yaml:"summary"`
+	HsbMethod   string `yaml:"x-hsb-method"`
+	Timeout     string `yaml:"x-timeout"`
+	Parameters  []struct {
+		Name     string         `yaml:"name"`
+		In       string         `yaml:"in"`
+		Required bool           `yaml:"required"`
+		Schema   *openapiSchema `yaml:"schema"`
+	} `yaml:"parameters"`
+	RequestBody *struct {
+		Content openapiContent `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses map[string]struct {
+		Content openapiContent `yaml:"content"`
+	} `yaml:"responses"`
+}
+
+type openapiDoc struct {
+	Package    string                                  `yaml:"x-package"`
+	Service    string                                  `yaml:"x-service"`
+	Config     string                                  `yaml:"x-config"`
+	Paths      map[string]map[string]*openapiOperation `yaml:"paths"`
+	Components struct {
+		Schemas map[string]*openapiSchema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+func (doc *openapiDoc) resolve(schema *openapiSchema) (*openapiSchema, error) {
+	for schema != nil && len(schema.Ref) > 0 {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		ref, ok := doc.Components.Schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("schema ref not find:%s", schema.Ref)
+		}
+		schema = ref
+	}
+	return schema, nil
+}
+
+func (doc *openapiDoc) goType(schema *openapiSchema) (string, error) {
+	schema, err := doc.resolve(schema)
+	if err != nil {
+		return "", err
+	}
+	if schema == nil {
+		return "interface{}", nil
+	}
+	switch schema.Type {
+	case "string":
+		return "string", nil
+	case "integer":
+		if schema.Format == "int32" {
+			return "int32", nil
+		}
+		return "int64", nil
+	case "number":
+		if schema.Format == "float" {
+			return "float32", nil
+		}
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		item, err := doc.goType(schema.Items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	case "object":
+		return "map[string]interface{}", nil
+	}
+	return "interface{}", nil
+}
+
+// validate 按 schema 约束生成 validate 标签
+func (doc *openapiDoc) validate(schema *openapiSchema, required bool) string {
+	var tags []string
+	if required {
+		tags = append(tags, "required")
+	}
+	schema, _ = doc.resolve(schema)
+	if schema == nil {
+		return strings.Join(tags, ",")
+	}
+	if schema.Minimum != nil {
+		tags = append(tags, fmt.Sprintf("gte=%v", *schema.Minimum))
+	}
+	if schema.Maximum != nil {
+		tags = append(tags, fmt.Sprintf("lte=%v", *schema.Maximum))
+	}
+	if schema.MinLength != nil {
+		tags = append(tags, fmt.Sprintf("min=%d", *schema.MinLength))
+	}
+	if schema.MaxLength != nil {
+		tags = append(tags, fmt.Sprintf("max=%d", *schema.MaxLength))
+	}
+	if len(schema.Enum) > 0 {
+		var enum []string
+		for _, val := range schema.Enum {
+			enum = append(enum, fmt.Sprint(val))
+		}
+		tags = append(tags, "oneof="+strings.Join(enum, " "))
+	}
+	if schema.Format == "email" {
+		tags = append(tags, "email")
+	}
+	if len(tags) > 0 && !required {
+		tags = append([]string{"omitempty"}, tags...)
+	}
+	return strings.Join(tags, ",")
+}
+
+func (doc *openapiDoc) fields(schema *openapiSchema) ([]*Field, error) {
+	schema, err := doc.resolve(schema)
+	if err != nil || schema == nil {
+		return nil, err
+	}
+	required := map[string]bool{}
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var fields []*Field
+	for _, name := range names {
+		prop := schema.Properties[name]
+		goType, err := doc.goType(prop)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, &Field{
+			Name:     name,
+			Type:     goType,
+			Validate: doc.validate(prop, required[name]),
+			Comment:  prop.Description,
+		})
+	}
+	return fields, nil
+}
+
+func (doc *openapiDoc) spec() (*Spec, error) {
+	spec := &Spec{
+		Package: doc.Package,
+		Service: doc.Service,
+		Config:  doc.Config,
+	}
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		methods := make([]string, 0, len(doc.Paths[path]))
+		for method := range doc.Paths[path] {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			operation := doc.Paths[path][method]
+			if len(operation.OperationId) == 0 {
+				return nil, fmt.Errorf("paths.%s.%s operationId is empty", path, method)
+			}
+			build := &Build{
+				Name:       exportName(operation.OperationId),
+				Path:       path,
+				Method:     operation.HsbMethod,
+				HttpMethod: strings.ToUpper(method),
+			}
+			if len(operation.Timeout) > 0 {
+				timeout, err := time.ParseDuration(operation.Timeout)
+				if err != nil {
+					return nil, fmt.Errorf("paths.%s.%s x-timeout is wrong", path, method)
+				}
+				build.Timeout = timeout
+			}
+			for _, param := range operation.Parameters {
+				if param.In != "query" {
+					continue
+				}
+				goType, err := doc.goType(param.Schema)
+				if err != nil {
+					return nil, err
+				}
+				build.Request = append(build.Request, &Field{
+					Name:     param.Name,
+					Type:     goType,
+					Validate: doc.validate(param.Schema, param.Required),
+				})
+			}
+			if operation.RequestBody != nil {
+				if content, ok := operation.RequestBody.Content["application/json"]; ok {
+					fields, err := doc.fields(content.Schema)
+					if err != nil {
+						return nil, err
+					}
+					build.Request = append(build.Request, fields...)
+				}
+			}
+			if response, ok := operation.Responses["200"]; ok {
+				if content, ok := response.Content["application/json"]; ok {
+					fields, err := doc.fields(content.Schema)
+					if err != nil {
+						return nil, err
+					}
+					build.Response = fields
+				}
+			}
+			spec.Builds = append(spec.Builds, build)
+		}
+	}
+	return spec, nil
+}
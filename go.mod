@@ -3,17 +3,24 @@ module github.com/hsbteam/rest_client
 go 1.17
 
 require (
+	github.com/andybalholm/brotli v1.0.4
+	github.com/go-playground/locales v0.14.0
+	github.com/go-playground/universal-translator v0.18.0
 	github.com/go-playground/validator/v10 v10.9.0
+	github.com/santhosh-tekuri/jsonschema/v5 v5.2.0
 	github.com/tidwall/gjson v1.12.1
+	github.com/tidwall/sjson v1.2.4
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	golang.org/x/text v0.3.6
+	google.golang.org/protobuf v1.28.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/go-playground/locales v0.14.0 // indirect
-	github.com/go-playground/universal-translator v0.18.0 // indirect
 	github.com/leodido/go-urn v1.2.1 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.0 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
 	golang.org/x/crypto v0.0.0-20210711020723-a769d52b0f97 // indirect
 	golang.org/x/sys v0.0.0-20210806184541-e5e7981a1069 // indirect
-	golang.org/x/text v0.3.6 // indirect
 )
@@ -0,0 +1,151 @@
+package rest_client
+
+import (
+	"github.com/tidwall/gjson"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JsonDataTimeLayouts JsonData.Time 未指定格式时依次尝试的时间格式
+var JsonDataTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// Get 获取子路径数据,错误会传递到返回的数据
+func (hand *JsonData) Get(path string) *JsonData {
+	if hand.err != nil {
+		return NewJsonDataFromError(hand.err)
+	}
+	if hand.Result == nil {
+		return NewJsonData(&gjson.Result{})
+	}
+	result := hand.Result.Get(path)
+	return NewJsonData(&result)
+}
+
+// Items 数组的全部元素,非数组或错误时返回nil
+func (hand *JsonData) Items() []*JsonData {
+	if hand.err != nil || hand.Result == nil || !hand.IsArray() {
+		return nil
+	}
+	results := hand.Array()
+	items := make([]*JsonData, 0, len(results))
+	for i := range results {
+		items = append(items, NewJsonData(&results[i]))
+	}
+	return items
+}
+
+// Money 按十进制精确转换金额,返回乘以 10^scale 后的整数,超出精度部分四舍五入
+// 如 "12.345" 按 scale 2 返回 1235,避免浮点误差
+func (hand *JsonData) Money(scale int) (int64, error) {
+	if hand.err != nil {
+		return 0, hand.err
+	}
+	if hand.Result == nil || (hand.Type != gjson.Number && hand.Type != gjson.String) {
+		return 0, NewRestClientError("23", "json data is not money:"+hand.raw())
+	}
+	str := strings.TrimSpace(hand.String())
+	if hand.Type == gjson.Number && len(hand.Raw) > 0 {
+		str = hand.Raw
+	}
+	sign := ""
+	if strings.HasPrefix(str, "-") || strings.HasPrefix(str, "+") {
+		if str[0] == '-' {
+			sign = "-"
+		}
+		str = str[1:]
+	}
+	intPart, fracPart := str, ""
+	if i := strings.Index(str, "."); i >= 0 {
+		intPart, fracPart = str[0:i], str[i+1:]
+	}
+	if len(intPart) == 0 && len(fracPart) == 0 || !jsonDataDigits(intPart) || !jsonDataDigits(fracPart) {
+		return 0, NewRestClientError("23", "json data is not money:"+hand.raw())
+	}
+	roundUp := false
+	if len(fracPart) > scale {
+		roundUp = fracPart[scale] >= '5'
+		fracPart = fracPart[0:scale]
+	} else {
+		fracPart += strings.Repeat("0", scale-len(fracPart))
+	}
+	var val int64
+	if digits := intPart + fracPart; len(digits) > 0 {
+		var err error
+		if val, err = strconv.ParseInt(sign+digits, 10, 64); err != nil {
+			return 0, NewRestClientError("23", "json data money overflow:"+hand.raw())
+		}
+	}
+	if roundUp {
+		if sign == "-" {
+			val--
+		} else {
+			val++
+		}
+	}
+	return val, nil
+}
+
+// jsonDataDigits 是否全部为数字,空字符串返回true
+func jsonDataDigits(str string) bool {
+	for _, c := range str {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Time 解析时间,数字按UNIX时间戳处理(超过 1e12 按毫秒),字符串按 layouts 依次尝试
+// @param layouts 不传时使用 JsonDataTimeLayouts,无时区的格式按本地时区解析
+func (hand *JsonData) Time(layouts ...string) (time.Time, error) {
+	if hand.err != nil {
+		return time.Time{}, hand.err
+	}
+	if hand.Result == nil {
+		return time.Time{}, NewRestClientError("23", "json data is not time:")
+	}
+	if hand.Type == gjson.Number {
+		unix := hand.Int()
+		if unix > 1e12 || unix < -1e12 {
+			return time.Unix(0, unix*int64(time.Millisecond)), nil
+		}
+		return time.Unix(unix, 0), nil
+	}
+	if hand.Type == gjson.String {
+		if layouts == nil {
+			layouts = JsonDataTimeLayouts
+		}
+		for _, layout := range layouts {
+			if tmp, err := time.ParseInLocation(layout, hand.Str, time.Local); err == nil {
+				return tmp, nil
+			}
+		}
+	}
+	return time.Time{}, NewRestClientError("23", "json data is not time:"+hand.raw())
+}
+
+// Enum 按映射转换枚举值,值不在映射中时返回错误
+func (hand *JsonData) Enum(values map[string]interface{}) (interface{}, error) {
+	if hand.err != nil {
+		return nil, hand.err
+	}
+	if hand.Result != nil && hand.Exists() {
+		if val, ok := values[hand.String()]; ok {
+			return val, nil
+		}
+	}
+	return nil, NewRestClientError("23", "json data enum not support:"+hand.raw())
+}
+
+// raw 原始内容,用于错误信息
+func (hand *JsonData) raw() string {
+	if hand.Result == nil {
+		return ""
+	}
+	return hand.Raw
+}
@@ -0,0 +1,83 @@
+package rest_client
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestJsonDataUnmarshal(t *testing.T) {
+	var tmp struct {
+		Id    *JsonData `json:"id"`
+		Name  *JsonData `json:"name"`
+		Tags  *JsonData `json:"tags"`
+		Attr  *JsonData `json:"attr"`
+		Price *JsonData `json:"price"`
+	}
+	body := `{"id":12,"name":"a\"b","tags":["x","y"],"attr":{"k":1},"price":"0.1"}`
+	if err := NewJsonResult(body, "").GetStruct("", &tmp); err != nil {
+		t.Fatal(err)
+	}
+	if !tmp.Tags.IsArray() || !tmp.Attr.IsObject() || tmp.Id.Int() != 12 || tmp.Name.String() != `a"b` {
+		t.Error("json data type lost")
+	}
+	if tmp.Attr.Get("k").Int() != 1 || len(tmp.Tags.Items()) != 2 || tmp.Tags.Items()[1].String() != "y" {
+		t.Error("json data get wrong")
+	}
+	out, _ := json.Marshal(tmp)
+	if string(out) != body {
+		t.Errorf("json data marshal wrong:%s", out)
+	}
+	out, err := json.Marshal(NewJsonResult(body, "").GetData(""))
+	if err != nil || string(out) != strconv.Quote(body) {
+		t.Errorf("json data without raw marshal wrong:%s %v", out, err)
+	}
+	out, _ = json.Marshal(NewJsonResult(body, "").GetData("none"))
+	if string(out) != "null" {
+		t.Errorf("json data not exists marshal wrong:%s", out)
+	}
+}
+
+func TestJsonDataAccessor(t *testing.T) {
+	data := NewJsonResult(`{"a":19.99,"b":"0.125","c":-1.005,"d":"1e3","t":1600000000,"tm":1600000000123,"ts":"2020-09-13 12:26:40","s":2,"o":{"k":[1,2]}}`, "")
+	for path, want := range map[string]int64{"a": 1999, "b": 13, "c": -101} {
+		if val, err := data.GetData(path).Money(2); err != nil || val != want {
+			t.Errorf("money %s wrong:%d %v", path, val, err)
+		}
+	}
+	if _, err := data.GetData("d").Money(2); err == nil {
+		t.Error("money must fail")
+	}
+	if tm, _ := data.GetData("t").Time(); tm.Unix() != 1600000000 {
+		t.Error("unix time wrong")
+	}
+	if tm, _ := data.GetData("tm").Time(); tm.UnixNano() != 1600000000123*int64(time.Millisecond) {
+		t.Error("unix ms time wrong")
+	}
+	if tm, err := data.GetData("ts").Time(); err != nil || tm.Format("2006-01-02 15:04:05") != "2020-09-13 12:26:40" {
+		t.Errorf("time layout wrong:%v", err)
+	}
+	if _, err := data.GetData("ts").Time(time.RFC3339); err == nil {
+		t.Error("time layout must fail")
+	}
+	status := map[string]interface{}{"1": "wait", "2": "done"}
+	if val, _ := data.GetData("s").Enum(status); val != "done" {
+		t.Error("enum wrong")
+	}
+	if _, err := data.GetData("a").Enum(status); err == nil {
+		t.Error("enum must fail")
+	}
+
+	if items := data.GetData("o").Get("k").Items(); len(items) != 2 || items[1].Int() != 2 {
+		t.Error("json data items wrong")
+	}
+
+	errData := NewJsonResultFromError(NewRestClientError("1", "err")).GetData("a")
+	if errData.Get("b").Err() == nil || errData.Items() != nil {
+		t.Error("json data error must propagate")
+	}
+	if _, err := errData.Get("b").Money(2); err == nil {
+		t.Error("json data error must propagate")
+	}
+}
@@ -1,10 +1,10 @@
 package rest_client
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	"github.com/tidwall/gjson"
 	"reflect"
@@ -17,13 +17,14 @@ type JsonResult struct {
 	basePath string
 	body     string
 	err      error
+	cache    *jsonResultCache
 }
 
 // NewJsonResult 解析一个JSON字符串为JSON结果
 // @param jsonBody JSON内容
 // @param basePath 从某个节点获取,传入空字符串表示根节点获取
 func NewJsonResult(jsonBody string, basePath string) *JsonResult {
-	return &JsonResult{body: jsonBody, basePath: basePath}
+	return &JsonResult{body: jsonBody, basePath: basePath, cache: &jsonResultCache{}}
 }
 
 // NewJsonResultFromError 创建一个错误JSON结果
@@ -36,10 +37,14 @@ func (res *JsonResult) Err() error {
 	return res.err
 }
 
-// JsonValid JSON校验结构
+// JsonValid JSON校验结构,通过 NewJsonValid 创建可注册自定义校验及翻译
 type JsonValid struct {
 	//外部定义校验结构
 	valid *validator.Validate
+	//错误信息翻译,未设置时使用 validator 默认信息
+	trans ut.Translator
+	//校验错误中需隐藏值的字段
+	redactFields []string
 	//上下文通过此结构透传,如果放到 GetStruct 跟 GetData 上参数太多,实用不方便
 	Context context.Context
 }
@@ -48,23 +53,100 @@ type JsonDataToType interface {
 	JsonDataToType(field string, result ToJsonData) interface{}
 }
 
-func defaultStruct(val *reflect.Value) {
-	for i := 0; i < val.NumField(); i++ {
-		tVal := val.Field(i)
-		//println(tVal.Kind())
-		switch tVal.Kind() {
-		case reflect.Chan, reflect.Func, reflect.Map, reflect.Ptr, reflect.UnsafePointer, reflect.Interface, reflect.Slice:
-			if tVal.IsNil() {
-				if jDat, ok := tVal.Interface().(JsonDataDefault); ok {
-					defVal := jDat.JsonDataDefault()
-					tVal.Set(reflect.ValueOf(defVal))
+// newJsonValidate 创建校验结构,错误信息中的字段名使用JSON名称
+func newJsonValidate() *validator.Validate {
+	valid := validator.New()
+	valid.RegisterTagNameFunc(jsonFieldName)
+	return valid
+}
+
+// jsonFieldName 结构字段对应的JSON名称
+func jsonFieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	if len(name) == 0 {
+		return field.Name
+	}
+	return name
+}
+
+var jsonDataType = reflect.TypeOf(JsonData{})
+var toJsonDataType = reflect.TypeOf((*ToJsonData)(nil)).Elem()
+
+// jsonStructWalk 递归处理结构中的 JsonData 字段,校验后为nil的字段设置默认值,校验失败的字段记录到 vErr
+// @return 是否包含非 JsonData 字段,包含时需整体校验
+func jsonStructWalk(val reflect.Value, path string, jv *JsonValid, vErr *ValidationError) bool {
+	hasOther := false
+	retH := val.Type()
+	for i := 0; i < retH.NumField(); i++ {
+		field := retH.Field(i)
+		if len(field.PkgPath) > 0 {
+			continue
+		}
+		fPath := pathCreate(path, jsonFieldName(field))
+		vTmp := val.Field(i)
+		if !field.Type.Implements(toJsonDataType) {
+			hasOther = true
+			jsonValueWalk(vTmp, fPath, jv, vErr)
+			continue
+		}
+		vTag := field.Tag.Get("validate")
+		isNil := false
+		switch vTmp.Kind() {
+		case reflect.Ptr, reflect.Interface:
+			isNil = vTmp.IsNil()
+		}
+		if len(vTag) > 0 {
+			vVal := vTmp.Interface()
+			if !isNil && val.CanAddr() {
+				if tVal, ok := val.Addr().Interface().(JsonDataToType); ok {
+					vVal = tVal.JsonDataToType(field.Name, vTmp.Interface().(ToJsonData))
 				}
 			}
-		case reflect.Struct:
-			//结构赋值待定...
-			//if reflect.PtrTo(tVal.Type()).Implements(reflect.TypeOf((*JsonDataDefault)(nil)).Elem()) {
-			//
-			//}
+			if err := jv.varValid(vVal, vTag); err != nil {
+				jv.appendVarError(vErr, fPath, field.Name, vVal, err)
+			}
+		}
+		if isNil && vTmp.CanSet() {
+			if jDat, ok := vTmp.Interface().(JsonDataDefault); ok {
+				vTmp.Set(reflect.ValueOf(jDat.JsonDataDefault()))
+			}
+		}
+	}
+	return hasOther
+}
+
+// jsonValueWalk 递归处理嵌套的结构、切片及map
+func jsonValueWalk(val reflect.Value, path string, jv *JsonValid, vErr *ValidationError) {
+	switch val.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !val.IsNil() {
+			jsonValueWalk(val.Elem(), path, jv, vErr)
+		}
+	case reflect.Struct:
+		if val.Type() != jsonDataType {
+			jsonStructWalk(val, path, jv, vErr)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < val.Len(); i++ {
+			jsonValueWalk(val.Index(i), fmt.Sprintf("%s[%d]", path, i), jv, vErr)
+		}
+	case reflect.Map:
+		iter := val.MapRange()
+		for iter.Next() {
+			elem := iter.Value()
+			kPath := pathCreate(path, fmt.Sprint(iter.Key().Interface()))
+			if elem.Kind() != reflect.Struct {
+				jsonValueWalk(elem, kPath, jv, vErr)
+				continue
+			}
+			//map中的结构不可寻址,复制处理后写回
+			tmp := reflect.New(elem.Type()).Elem()
+			tmp.Set(elem)
+			jsonValueWalk(tmp, kPath, jv, vErr)
+			val.SetMapIndex(iter.Key(), tmp)
 		}
 	}
 }
@@ -74,18 +156,17 @@ func (res *JsonResult) GetStruct(path string, structPtr interface{}, jsonValid .
 	if res.err != nil {
 		return res.err
 	}
-	body := res.body
 	var param string
 	path = pathCreate(res.basePath, path)
 	if len(path) == 0 {
-		param = body
+		param = res.body
 	} else {
-		param = gjson.Get(body, path).String()
+		param = res.get(path).String()
 	}
 	if len(param) == 0 {
 		param = "{}"
 	}
-	dec := json.NewDecoder(bytes.NewBuffer([]byte(param)))
+	dec := json.NewDecoder(strings.NewReader(param))
 	dec.UseNumber()
 	err := dec.Decode(&structPtr)
 	if err != nil {
@@ -103,77 +184,26 @@ func (res *JsonResult) GetStruct(path string, structPtr interface{}, jsonValid .
 		return nil
 	}
 
-	var valid *validator.Validate
-	var ctx context.Context
-	if jsonValid != nil && len(jsonValid) > 0 {
-		valid = jsonValid[0].valid
-		ctx = jsonValid[0].Context
-	}
-	if valid == nil {
-		if res.valid == nil {
-			res.valid = validator.New()
-		}
-		valid = res.valid
-	}
-
-	retH := reflect.TypeOf(structPtr)
-	if retH.Kind() == reflect.Ptr {
-		retH = retH.Elem()
-	}
-	if retH.Kind() == reflect.Ptr {
-		retH = retH.Elem()
-	}
-	allJsonData := true
-	for i := 0; i < retH.NumField(); i++ {
-		var vErr error
-		vTmp := val.Field(i)
-		var vVal interface{}
-		switch vTmp.Kind() {
-		case reflect.Ptr, reflect.Interface, reflect.Slice:
-			if !vTmp.IsNil() {
-				vVal = vTmp.Interface()
-			}
-		}
-		if jData, ok := vVal.(ToJsonData); ok {
-			field := retH.Field(i)
-			vTag := field.Tag.Get("validate")
-			if tVal, ok := structPtr.(JsonDataToType); ok {
-				vVal := tVal.JsonDataToType(field.Name, jData)
-				if ctx == nil {
-					vErr = valid.Var(vVal, vTag)
-				} else {
-					vErr = valid.VarCtx(ctx, vVal, vTag)
-				}
-			} else {
-				if ctx == nil {
-					vErr = valid.Var(vVal, vTag)
-				} else {
-					vErr = valid.VarCtx(ctx, vVal, vTag)
-				}
-			}
-			if vErr != nil {
-				return NewRestClientError("20", fmt.Sprintf("path:%s field:%s tag:%s error:%s ", path, field.Name, vTag, vErr.Error()))
-			}
-		} else {
-			allJsonData = false
+	jv := res.jsonValid(jsonValid)
+	vErr := &ValidationError{}
+	if jsonStructWalk(val, path, jv, vErr) {
+		if err := jv.structValid(vErr, path, structPtr); err != nil {
+			return err
 		}
 	}
-	if allJsonData {
-		return nil
-	}
+	return vErr.orNil()
+}
 
-	var vErr error
-	if ctx == nil {
-		vErr = valid.Struct(structPtr)
-	} else {
-		vErr = valid.StructCtx(ctx, structPtr)
+// jsonValid 补全校验结构,未传入时使用默认校验
+func (res *JsonResult) jsonValid(jsonValid []*JsonValid) *JsonValid {
+	if res.valid == nil {
+		res.valid = newJsonValidate()
 	}
-
-	defaultStruct(&val)
-	if vErr != nil {
-		return vErr
+	var jv *JsonValid
+	if len(jsonValid) > 0 {
+		jv = jsonValid[0]
 	}
-	return nil
+	return jv.with(res.valid)
 }
 
 // JsonKey JSON获取KEY
@@ -202,40 +232,26 @@ func (res *JsonResult) GetData(key interface{}) *JsonData {
 	} else {
 		return NewJsonDataFromError(NewRestClientError("20", "dataKey type not support"))
 	}
-	body := res.body
 	_path := pathCreate(res.basePath, dKey.Path)
 	if len(_path) == 0 {
 		return NewJsonData(&gjson.Result{
 			Type: gjson.String,
-			Str:  body,
+			Str:  res.body,
 		})
 	}
-	data := gjson.Get(body, _path)
+	data := res.get(_path)
 	if len(dKey.Tag) > 0 {
-		var valid *validator.Validate
-		if dKey.JsonValid != nil {
-			valid = dKey.valid
-		}
-		if valid == nil {
-			if res.valid == nil {
-				res.valid = validator.New()
-			}
-			valid = res.valid
-		}
+		jv := res.jsonValid([]*JsonValid{dKey.JsonValid})
 		var val interface{}
 		if dKey.ToType == nil {
 			val = data.String()
 		} else {
 			val = dKey.ToType(&data)
 		}
-		var err error
-		if dKey.JsonValid != nil && dKey.Context != nil {
-			err = valid.VarCtx(dKey.Context, val, dKey.Tag)
-		} else {
-			err = valid.Var(val, dKey.Tag)
-		}
-		if err != nil {
-			return NewJsonDataFromError(NewRestClientError("20", fmt.Sprintf("path:%s tag:%s error:%s ", _path, dKey.Tag, err.Error())))
+		if err := jv.varValid(val, dKey.Tag); err != nil {
+			vErr := &ValidationError{}
+			jv.appendVarError(vErr, _path, "", val, err)
+			return NewJsonDataFromError(vErr)
 		}
 	}
 	return NewJsonData(&data)
@@ -262,20 +278,30 @@ func (hand *JsonData) ToJsonData() *JsonData {
 	return hand
 }
 
+// UnmarshalJSON 保留原始的JSON类型,数字、对象及数组可通过 Raw 获取原始内容
 func (hand *JsonData) UnmarshalJSON(data []byte) error {
 	if hand == nil {
 		return nil
 	}
-	jDat := JsonData{
-		&gjson.Result{
-			Type: gjson.String,
-			Str:  strings.Trim(string(data), "\""),
-		}, nil,
-	}
-	*hand = jDat
+	result := gjson.Parse(string(data))
+	*hand = JsonData{&result, nil}
 	return nil
 }
 
+// MarshalJSON 输出原始JSON内容,无原始内容时(如 GetData("") 的结果)按值编码
+func (hand *JsonData) MarshalJSON() ([]byte, error) {
+	if hand.err != nil {
+		return nil, hand.err
+	}
+	if hand.Result == nil {
+		return []byte("null"), nil
+	}
+	if len(hand.Raw) == 0 {
+		return json.Marshal(hand.Value())
+	}
+	return []byte(hand.Raw), nil
+}
+
 type JsonDataDefault interface {
 	JsonDataDefault() interface{}
 	//	JsonDataStructDefault()
@@ -0,0 +1,115 @@
+package rest_client
+
+import (
+	"github.com/tidwall/gjson"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// jsonResultCache JSON结果的解析缓存,按路径逐级为对象建立KEY索引,并缓存已查询的路径
+type jsonResultCache struct {
+	lock    sync.Mutex
+	parsed  bool
+	root    gjson.Result
+	objects map[string]map[string]gjson.Result
+	paths   map[string]gjson.Result
+}
+
+// get 查询路径,路径开头的普通KEY逐级从索引获取,其余部分从子内容查询
+func (cache *jsonResultCache) get(body string, path string) gjson.Result {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	if result, ok := cache.paths[path]; ok {
+		return result
+	}
+	if cache.paths == nil {
+		cache.paths = make(map[string]gjson.Result)
+	}
+	var result gjson.Result
+	if key, sub, ok := jsonPathFirst(path); ok {
+		if !cache.parsed {
+			cache.parsed = true
+			cache.root = gjson.Parse(body)
+		}
+		result = cache.walk("", cache.root, key, sub)
+	} else {
+		result = gjson.Get(body, path)
+	}
+	cache.paths[path] = result
+	return result
+}
+
+// walk 从父节点获取 key,再继续查询剩余路径
+func (cache *jsonResultCache) walk(prefix string, parent gjson.Result, key string, sub string) gjson.Result {
+	path := pathCreate(prefix, key)
+	result, ok := cache.paths[path]
+	if !ok {
+		if parent.IsObject() {
+			result = cache.object(prefix, parent)[key]
+		} else {
+			result = parent.Get(key)
+		}
+		cache.paths[path] = result
+	}
+	if len(sub) == 0 {
+		return result
+	}
+	if nKey, nSub, ok := jsonPathFirst(sub); ok {
+		return cache.walk(path, result, nKey, nSub)
+	}
+	return result.Get(sub)
+}
+
+// object 对象的KEY索引,每个对象只遍历一次
+func (cache *jsonResultCache) object(path string, result gjson.Result) map[string]gjson.Result {
+	if index, ok := cache.objects[path]; ok {
+		return index
+	}
+	index := make(map[string]gjson.Result)
+	result.ForEach(func(key, value gjson.Result) bool {
+		//重复的KEY与 gjson.Get 一致使用第一个
+		if _, ok := index[key.Str]; !ok {
+			index[key.Str] = value
+		}
+		return true
+	})
+	if cache.objects == nil {
+		cache.objects = make(map[string]map[string]gjson.Result)
+	}
+	cache.objects[path] = index
+	return index
+}
+
+// jsonPathFirst 拆分路径首段,首段包含通配符、转义、修饰符等特殊语法时返回false
+func jsonPathFirst(path string) (string, string, bool) {
+	key, sub := path, ""
+	if i := strings.IndexByte(path, '.'); i >= 0 {
+		key, sub = path[0:i], path[i+1:]
+	}
+	if len(key) == 0 || strings.ContainsAny(key, "*?\\#@|!=<>[]{}()\"',:") {
+		return "", "", false
+	}
+	return key, sub, true
+}
+
+// restBytesString []byte 转为 string 不复制内容,转换后 body 不可再修改
+func restBytesString(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	return *(*string)(unsafe.Pointer(&body))
+}
+
+// NewJsonResultBytes 同 NewJsonResult,直接使用 jsonBody 不复制内容,调用后 jsonBody 不可再修改
+func NewJsonResultBytes(jsonBody []byte, basePath string) *JsonResult {
+	return NewJsonResult(restBytesString(jsonBody), basePath)
+}
+
+// get 查询完整路径,相同路径只解析一次
+func (res *JsonResult) get(path string) gjson.Result {
+	if res.cache == nil {
+		return gjson.Get(res.body, path)
+	}
+	return res.cache.get(res.body, path)
+}
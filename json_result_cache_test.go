@@ -0,0 +1,114 @@
+package rest_client
+
+import (
+	"fmt"
+	"github.com/tidwall/gjson"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestJsonResultCache(t *testing.T) {
+	body := `{"a":{"b":[{"c":1},{"c":2}],"d.e":3},"a":{"b":9},"f":"x","g":[1,2,3]}`
+	res := NewJsonResult(body, "")
+	for _, path := range []string{"a.b.1.c", "a.b.#.c", "a.b.#(c==2).c", `a.d\.e`, "f", "g.#", "g|@reverse", "@this.f", "*.b.0.c", "x.y", "a.b|0"} {
+		want := gjson.Get(body, path)
+		for i := 0; i < 2; i++ {
+			if got := res.GetData(path); got.Raw != want.Raw || got.Type != want.Type {
+				t.Errorf("cache path %s wrong:%s %s", path, got.Raw, want.Raw)
+			}
+		}
+	}
+	if NewJsonResult(`[{"a":1}]`, "").GetData("0.a").Int() != 1 {
+		t.Error("array body wrong")
+	}
+
+	data := []byte(`{"data":{"name":"a"}}`)
+	var wg sync.WaitGroup
+	res = NewJsonResultBytes(data, "data")
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if res.GetData("name").String() != "a" {
+				t.Error("concurrent cache wrong")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// benchJsonBody 大的返回内容,用于对比逐个字段读取的性能
+func benchJsonBody() string {
+	items := make([]string, 0, 2000)
+	for i := 0; i < 2000; i++ {
+		items = append(items, fmt.Sprintf(`{"id":%d,"sku":"SKU%d","price":%d.5}`, i, i, i))
+	}
+	return `{"result":{"code":"200","state":"ok"},"data":{"items":[` + strings.Join(items, ",") +
+		`],"total":2000,"name":"list","user":{"id":1,"name":"u"}}}`
+}
+
+var benchJsonPaths = []string{"total", "name", "user.id", "user.name", "items.1999.sku"}
+
+func BenchmarkJsonResultGetData(b *testing.B) {
+	body := benchJsonBody()
+	b.Run("nocache", func(b *testing.B) {
+		//与未缓存的实现一致,每次查询都从完整内容解析
+		res := &JsonResult{body: body, basePath: "data"}
+		for i := 0; i < b.N; i++ {
+			for _, path := range benchJsonPaths {
+				res.GetData(path)
+			}
+		}
+	})
+	b.Run("cache", func(b *testing.B) {
+		res := NewJsonResult(body, "data")
+		for i := 0; i < b.N; i++ {
+			for _, path := range benchJsonPaths {
+				res.GetData(path)
+			}
+		}
+	})
+	b.Run("cache_new", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			res := NewJsonResult(body, "data")
+			for _, path := range benchJsonPaths {
+				res.GetData(path)
+			}
+		}
+	})
+}
+
+func BenchmarkJsonResultGetStruct(b *testing.B) {
+	body := benchJsonBody()
+	var user struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	b.Run("nocache", func(b *testing.B) {
+		res := &JsonResult{body: body}
+		for i := 0; i < b.N; i++ {
+			_ = res.GetStruct("data.user", &user)
+		}
+	})
+	b.Run("cache", func(b *testing.B) {
+		res := NewJsonResult(body, "")
+		for i := 0; i < b.N; i++ {
+			_ = res.GetStruct("data.user", &user)
+		}
+	})
+}
+
+func BenchmarkNewJsonResultBytes(b *testing.B) {
+	body := []byte(benchJsonBody())
+	b.Run("string", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			NewJsonResult(string(body), "data").GetData("total")
+		}
+	})
+	b.Run("bytes", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			NewJsonResultBytes(body, "data").GetData("total")
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/tidwall/gjson"
 	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -85,3 +86,51 @@ func TestNewJsonResultErr(t *testing.T) {
 		t.Error("json parse error wrong")
 	}
 }
+
+type tmpItem struct {
+	Sku   *JsonData `json:"sku" validate:"required"`
+	Price *JsonData `json:"price" validate:"gt=0"`
+	Note  *JsonData `json:"note"`
+}
+
+func (receiver *tmpItem) JsonDataToType(field string, result ToJsonData) interface{} {
+	if field == "Price" {
+		return result.ToJsonData().Float()
+	}
+	return result.ToJsonData().String()
+}
+
+type tmpOrder struct {
+	Id    int                 `json:"id" validate:"gt=0"`
+	Items []tmpItem           `json:"items" validate:"dive"`
+	Main  *tmpItem            `json:"main"`
+	Group map[string]*tmpItem `json:"group"`
+}
+
+func TestJsonResultNestedStruct(t *testing.T) {
+	read := NewJsonResult(`{"data":{"id":1,"main":{"sku":"m","price":1},"items":[{"sku":"a","price":1.5},{"sku":"b","price":2}],"group":{"g":{"sku":"c","price":3}}}}`, "data")
+	var order tmpOrder
+	if err := read.GetStruct("", &order); err != nil {
+		t.Fatal(err)
+	}
+	if order.Items[1].Price.Float() != 2 || order.Group["g"].Sku.String() != "c" {
+		t.Error("nested struct decode wrong")
+	}
+	if order.Items[0].Note == nil || order.Main.Note == nil || order.Group["g"].Note == nil {
+		t.Error("nested json data default wrong")
+	}
+
+	read = NewJsonResult(`{"data":{"id":1,"items":[{"sku":"a","price":1},{"sku":"b","price":0}]}}`, "")
+	err := read.GetStruct("data", &tmpOrder{})
+	if err == nil || !strings.Contains(err.Error(), "path:data.items[1].price ") {
+		t.Errorf("nested valid path wrong:%v", err)
+	}
+	err = NewJsonResult(`{"id":1,"group":{"g":{"price":1}}}`, "").GetStruct("", &tmpOrder{})
+	if err == nil || !strings.Contains(err.Error(), "path:group.g.sku ") {
+		t.Errorf("map valid path wrong:%v", err)
+	}
+	err = NewJsonResult(`{"data":{"id":0}}`, "data").GetStruct("", &tmpOrder{})
+	if err == nil || !strings.Contains(err.Error(), "path:data.id ") {
+		t.Errorf("struct valid path wrong:%v", err)
+	}
+}
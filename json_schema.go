@@ -0,0 +1,178 @@
+package rest_client
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JsonSchema JSON Schema(draft 2020-12),用于校验动态结构的返回内容
+type JsonSchema struct {
+	schema *jsonschema.Schema
+}
+
+// NewJsonSchema 编译 JSON Schema,未指定 $schema 时使用 draft 2020-12, format 会做校验
+func NewJsonSchema(schema string) (*JsonSchema, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	compiler.AssertFormat = true
+	if err := compiler.AddResource("rest_client.schema.json", strings.NewReader(schema)); err != nil {
+		return nil, NewRestClientError("22", "json schema is wrong:"+err.Error())
+	}
+	tmp, err := compiler.Compile("rest_client.schema.json")
+	if err != nil {
+		return nil, NewRestClientError("22", "json schema is wrong:"+err.Error())
+	}
+	return &JsonSchema{schema: tmp}, nil
+}
+
+// MustJsonSchema 同 NewJsonSchema,编译失败时 panic,用于包级变量
+func MustJsonSchema(schema string) *JsonSchema {
+	tmp, err := NewJsonSchema(schema)
+	if err != nil {
+		panic(err)
+	}
+	return tmp
+}
+
+// Valid 校验JSON内容,失败返回 ValidationError
+// @param path 校验内容所在路径,用于错误信息中的完整路径
+func (schema *JsonSchema) Valid(body string, path string) error {
+	var doc interface{}
+	if len(strings.TrimSpace(body)) > 0 {
+		dec := json.NewDecoder(bytes.NewReader([]byte(body)))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return err
+		}
+	}
+	err := schema.schema.Validate(doc)
+	if err == nil {
+		return nil
+	}
+	sErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return err
+	}
+	vErr := &ValidationError{}
+	var walk func(sErr *jsonschema.ValidationError)
+	walk = func(sErr *jsonschema.ValidationError) {
+		if len(sErr.Causes) > 0 {
+			for _, cause := range sErr.Causes {
+				walk(cause)
+			}
+			return
+		}
+		fPath, value := jsonPointerPath(doc, sErr.InstanceLocation)
+		keyword := sErr.KeywordLocation
+		if i := strings.LastIndex(keyword, "/"); i >= 0 {
+			keyword = keyword[i+1:]
+		}
+		vErr.Fields = append(vErr.Fields, &ValidationFieldError{
+			Path:    pathCreate(path, fPath),
+			Tag:     keyword,
+			Value:   value,
+			Message: sErr.Message,
+		})
+	}
+	walk(sErr)
+	return vErr.orNil()
+}
+
+// jsonPointerPath JSON Pointer 转为 gjson 路径,数组下标为 [n],同时返回对应的值
+func jsonPointerPath(doc interface{}, pointer string) (string, interface{}) {
+	path := ""
+	value := doc
+	for _, seg := range strings.Split(pointer, "/") {
+		if len(seg) == 0 {
+			continue
+		}
+		seg = strings.ReplaceAll(strings.ReplaceAll(seg, "~1", "/"), "~0", "~")
+		switch tValue := value.(type) {
+		case []interface{}:
+			path += "[" + seg + "]"
+			value = nil
+			if i, err := strconv.Atoi(seg); err == nil && i >= 0 && i < len(tValue) {
+				value = tValue[i]
+			}
+		case map[string]interface{}:
+			path = pathCreate(path, seg)
+			value = tValue[seg]
+		default:
+			path = pathCreate(path, seg)
+			value = nil
+		}
+	}
+	if num, ok := value.(json.Number); ok {
+		if tmp, err := num.Float64(); err == nil {
+			value = tmp
+		}
+	}
+	return path, value
+}
+
+// ValidSchema 使用 JSON Schema 校验 basePath 下的内容
+func (res *JsonResult) ValidSchema(schema *JsonSchema) error {
+	if res.err != nil {
+		return res.err
+	}
+	body := res.body
+	if len(res.basePath) > 0 {
+		body = res.get(res.basePath).Raw
+	}
+	return schema.Valid(body, res.basePath)
+}
+
+// WithJsonSchema 设置 JsonResult 时自动校验的 JSON Schema,在 CheckJsonResult 之后校验
+func (res *RestResult) WithJsonSchema(schema *JsonSchema) *RestResult {
+	res.schema = schema
+	return res
+}
+
+// restJsonSchema 按接口KEY注册的 JSON Schema
+type restJsonSchema struct {
+	schema    *JsonSchema
+	autoCheck bool
+}
+
+// RegisterJsonSchema 按接口KEY注册返回内容的 JSON Schema
+// @param autoCheck 为 true 时 RestResult.JsonResult 自动校验,否则通过 RestClient.JsonSchema 获取后手动校验
+func (c *RestClientManager) RegisterJsonSchema(api RestApi, key interface{}, schema *JsonSchema, autoCheck bool) error {
+	restKey, err := newApiRestKey(api, key)
+	if err != nil {
+		return err
+	}
+	c.tableLock.Lock()
+	defer c.tableLock.Unlock()
+	if c.schemas == nil {
+		c.schemas = make(map[reflect.Type]map[restKeyIndex]*restJsonSchema)
+	}
+	apiType := reflect.TypeOf(api)
+	if c.schemas[apiType] == nil {
+		c.schemas[apiType] = make(map[restKeyIndex]*restJsonSchema)
+	}
+	c.schemas[apiType][restKey.index()] = &restJsonSchema{schema: schema, autoCheck: autoCheck}
+	return nil
+}
+
+// getJsonSchema 获取已注册的 JSON Schema,未注册时返回nil
+func (c *RestClientManager) getJsonSchema(api RestApi, key RestKey) *restJsonSchema {
+	c.tableLock.RLock()
+	defer c.tableLock.RUnlock()
+	return c.schemas[reflect.TypeOf(api)][key.index()]
+}
+
+// JsonSchema 获取接口KEY注册的 JSON Schema,未注册时返回nil
+func (client *RestClient) JsonSchema(key interface{}) *JsonSchema {
+	restKey, err := newApiRestKey(client.Api, key)
+	if err != nil {
+		return nil
+	}
+	if item := client.manager.getJsonSchema(client.Api, restKey); item != nil {
+		return item.schema
+	}
+	return nil
+}
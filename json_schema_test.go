@@ -0,0 +1,87 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const testOrderSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["id", "items"],
+	"properties": {
+		"id": {"type": "integer", "minimum": 1},
+		"email": {"type": "string", "format": "email"},
+		"items": {
+			"type": "array",
+			"items": {
+				"type": "object",
+				"required": ["sku"],
+				"properties": {"price": {"type": "number", "exclusiveMinimum": 0}}
+			}
+		}
+	}
+}`
+
+func TestJsonSchema(t *testing.T) {
+	schema := MustJsonSchema(testOrderSchema)
+	if err := NewJsonResult(`{"data":{"id":1,"items":[{"sku":"a","price":1}]}}`, "data").ValidSchema(schema); err != nil {
+		t.Fatal(err)
+	}
+	err := NewJsonResult(`{"data":{"id":0,"email":"bad","items":[{"sku":"a","price":1},{"price":-1}]}}`, "data").ValidSchema(schema)
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("schema error type wrong:%v", err)
+	}
+	if field := vErr.Field("data.items[1].price"); field == nil || field.Tag != "exclusiveMinimum" || field.Value != float64(-1) {
+		t.Errorf("schema price error wrong:%v", err)
+	}
+	for _, path := range []string{"data.id", "data.email", "data.items[1]"} {
+		if vErr.Field(path) == nil {
+			t.Errorf("schema error path not find:%s %v", path, err)
+		}
+	}
+	if err := NewJsonResult(`{"a":1}`, "data").ValidSchema(schema); err == nil {
+		t.Error("missing path must fail")
+	}
+	if _, err := NewJsonSchema(`{"type":1}`); err == nil {
+		t.Error("schema compile must fail")
+	}
+}
+
+func TestJsonSchemaAutoCheck(t *testing.T) {
+	body := `{"result":{"code":"200","state":"ok"},"data":{"id":1,"items":[{"sku":"a","price":0}]}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	schema := MustJsonSchema(testOrderSchema)
+	api := client.NewApi(&testDome1{})
+	if err := (<-api.Do(context.Background(), test1, nil)).JsonResult("data").Err(); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.RegisterJsonSchema(&testDome1{}, test1, schema, false); err != nil {
+		t.Fatal(err)
+	}
+	if api.JsonSchema(test1) != schema {
+		t.Error("registered schema wrong")
+	}
+	if err := (<-api.Do(context.Background(), test1, nil)).JsonResult("data").Err(); err != nil {
+		t.Errorf("schema must not auto check:%v", err)
+	}
+	_ = client.RegisterJsonSchema(&testDome1{}, test1, schema, true)
+	res := <-api.Do(context.Background(), test1, nil)
+	data := res.JsonResult("data")
+	var vErr *ValidationError
+	if !errors.As(data.Err(), &vErr) || vErr.Field("data.items[0].price") == nil || res.Err() != data.Err() {
+		t.Errorf("schema auto check wrong:%v", data.Err())
+	}
+}
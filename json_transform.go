@@ -0,0 +1,208 @@
+package rest_client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// JsonProjectField 投影字段
+type JsonProjectField struct {
+	From string //源路径,相对 basePath,可使用 gjson 语法及修饰符
+	To   string //新文档中的路径,使用 sjson 语法,为空时同 From
+}
+
+// Body JSON原始内容
+func (res *JsonResult) Body() string {
+	return res.body
+}
+
+// document basePath 下的原始内容,用于生成新的JSON结果
+func (res *JsonResult) document() string {
+	if len(res.basePath) == 0 {
+		return res.body
+	}
+	return res.get(res.basePath).Raw
+}
+
+// transform 基于 basePath 下的内容生成新的JSON结果,错误时传递错误
+func (res *JsonResult) transform(fn func(doc string) (string, error)) *JsonResult {
+	if res.err != nil {
+		return NewJsonResultFromError(res.err)
+	}
+	doc, err := fn(res.document())
+	if err != nil {
+		return NewJsonResultFromError(NewRestClientError("25", "json transform fail:"+err.Error()))
+	}
+	result := NewJsonResult(doc, "")
+	result.valid = res.valid
+	return result
+}
+
+// Project 只保留指定路径的内容,生成新的JSON结果,路径不存在时跳过
+func (res *JsonResult) Project(paths ...string) *JsonResult {
+	fields := make([]*JsonProjectField, 0, len(paths))
+	for _, path := range paths {
+		fields = append(fields, &JsonProjectField{From: path})
+	}
+	return res.ProjectAs(fields...)
+}
+
+// ProjectAs 按字段投影到新的路径,生成新的JSON结果,源路径不存在时跳过
+func (res *JsonResult) ProjectAs(fields ...*JsonProjectField) *JsonResult {
+	return res.transform(func(doc string) (string, error) {
+		out := "{}"
+		for _, field := range fields {
+			value := res.get(pathCreate(res.basePath, field.From))
+			if !value.Exists() {
+				continue
+			}
+			to := field.To
+			if len(to) == 0 {
+				to = field.From
+			}
+			var err error
+			if out, err = sjson.SetRaw(out, to, value.Raw); err != nil {
+				return "", err
+			}
+		}
+		return out, nil
+	})
+}
+
+// Rename 移动字段到新的路径,源路径不存在时不修改
+func (res *JsonResult) Rename(from string, to string) *JsonResult {
+	return res.transform(func(doc string) (string, error) {
+		value := gjson.Get(doc, from)
+		if !value.Exists() {
+			return doc, nil
+		}
+		doc, err := sjson.Delete(doc, from)
+		if err != nil {
+			return "", err
+		}
+		return sjson.SetRaw(doc, to, value.Raw)
+	})
+}
+
+// Delete 删除指定路径的字段
+func (res *JsonResult) Delete(paths ...string) *JsonResult {
+	return res.transform(func(doc string) (string, error) {
+		var err error
+		for _, path := range paths {
+			if doc, err = sjson.Delete(doc, path); err != nil {
+				return "", err
+			}
+		}
+		return doc, nil
+	})
+}
+
+// Set 设置字段值, value 为 *JsonResult 或 *JsonData 时使用其原始内容,其错误会传递到返回结果
+func (res *JsonResult) Set(path string, value interface{}) *JsonResult {
+	var raw *string
+	switch tValue := value.(type) {
+	case *JsonResult:
+		if tValue.err != nil {
+			return NewJsonResultFromError(tValue.err)
+		}
+		tmp := tValue.document()
+		raw = &tmp
+	case *JsonData:
+		//无原始内容时(如 GetData("") 的结果)按值编码
+		tmp, err := tValue.MarshalJSON()
+		if err != nil {
+			return NewJsonResultFromError(err)
+		}
+		tmpRaw := string(tmp)
+		raw = &tmpRaw
+	}
+	return res.transform(func(doc string) (string, error) {
+		if raw == nil {
+			return sjson.Set(doc, path, value)
+		}
+		if len(*raw) == 0 {
+			return sjson.SetRaw(doc, path, "null")
+		}
+		return sjson.SetRaw(doc, path, *raw)
+	})
+}
+
+// Modify 依次应用 gjson 修饰符生成新的JSON结果,如 @reverse、@pretty,可通过 gjson.AddModifier 注册自定义修饰符
+// 修饰符不存在或结果为空时返回错误
+func (res *JsonResult) Modify(modifiers ...string) *JsonResult {
+	return res.transform(func(doc string) (string, error) {
+		for _, modifier := range modifiers {
+			value := gjson.Get(doc, modifier)
+			if !value.Exists() {
+				return "", errors.New("modifier " + modifier + " result not exists")
+			}
+			doc = value.Raw
+		}
+		return doc, nil
+	})
+}
+
+// MergeJsonResult 合并多个JSON结果的 basePath 下的内容,对象逐级合并,其他类型后面的覆盖前面的
+// 任一结果错误时返回该错误
+func MergeJsonResult(results ...*JsonResult) *JsonResult {
+	out := ""
+	for _, res := range results {
+		if res.err != nil {
+			return NewJsonResultFromError(res.err)
+		}
+		doc := res.document()
+		if len(doc) == 0 {
+			continue
+		}
+		if len(out) == 0 {
+			out = doc
+			continue
+		}
+		out = jsonMergeRaw(gjson.Parse(out), gjson.Parse(doc))
+	}
+	if len(out) == 0 {
+		out = "{}"
+	}
+	return NewJsonResult(out, "")
+}
+
+// jsonMergeRaw 合并两个JSON值,都为对象时逐个KEY合并
+func jsonMergeRaw(dst gjson.Result, src gjson.Result) string {
+	if !dst.IsObject() || !src.IsObject() {
+		return src.Raw
+	}
+	var keys []string
+	values := make(map[string]string)
+	dst.ForEach(func(key, value gjson.Result) bool {
+		if _, ok := values[key.Str]; !ok {
+			keys = append(keys, key.Str)
+		}
+		values[key.Str] = value.Raw
+		return true
+	})
+	src.ForEach(func(key, value gjson.Result) bool {
+		if old, ok := values[key.Str]; ok {
+			values[key.Str] = jsonMergeRaw(gjson.Parse(old), value)
+		} else {
+			keys = append(keys, key.Str)
+			values[key.Str] = value.Raw
+		}
+		return true
+	})
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.WriteString(values[key])
+	}
+	buf.WriteByte('}')
+	return buf.String()
+}
@@ -0,0 +1,68 @@
+package rest_client
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJsonResultProject(t *testing.T) {
+	res := NewJsonResult(`{"result":{"code":"200"},"data":{"id":1,"name":"a","user":{"id":2,"name":"u","secret":"s"},"items":[{"sku":"a"},{"sku":"b"}]}}`, "data")
+	if body := res.Project("id", "user.name", "none").Body(); body != `{"id":1,"user":{"name":"u"}}` {
+		t.Errorf("project wrong:%s", body)
+	}
+	out := res.ProjectAs(
+		&JsonProjectField{From: "user.id", To: "user_id"},
+		&JsonProjectField{From: "items.#.sku", To: "skus"},
+		&JsonProjectField{From: "items|@reverse", To: "reverse"},
+	)
+	if body := out.Body(); body != `{"user_id":2,"skus":["a","b"],"reverse":[{"sku":"b"},{"sku":"a"}]}` {
+		t.Errorf("project as wrong:%s", body)
+	}
+	if out.GetData("skus.1").String() != "b" {
+		t.Error("project result get wrong")
+	}
+	if body := res.Rename("user.name", "user_name").Delete("items", "user.secret").Body(); body != `{"id":1,"name":"a","user":{"id":2},"user_name":"u"}` {
+		t.Errorf("rename wrong:%s", body)
+	}
+	if body := NewJsonResult(`{"list":[3,1]}`, "").Modify("list", "@reverse").Body(); body != `[1,3]` {
+		t.Errorf("modify wrong:%s", body)
+	}
+	if err := NewJsonResult(`{"list":[3,1]}`, "").Modify("@notExists").Err(); err == nil {
+		t.Error("unknown modifier must fail")
+	} else if rErr, ok := err.(*RestClientError); !ok || rErr.Code != "25" {
+		t.Error(err)
+	}
+
+	other := NewJsonResult(`{"data":{"stock":5}}`, "data")
+	if body := res.Project("id").Set("stock", other).Set("tag", "new").Set("first", res.GetData("items.0")).Body(); body != `{"id":1,"stock":{"stock":5},"tag":"new","first":{"sku":"a"}}` {
+		t.Errorf("set wrong:%s", body)
+	}
+
+	if body := NewJsonResult(`{}`, "").Set("raw", NewJsonResult(`{"a":1}`, "").GetData("")).Body(); body != `{"raw":"{\"a\":1}"}` {
+		t.Errorf("set json data without raw wrong:%s", body)
+	}
+
+	fail := NewJsonResultFromError(NewRestClientError("1", "fail"))
+	if res.Set("x", fail).Err() == nil || fail.Project("id").Err() == nil || fail.Modify("@reverse").Err() == nil {
+		t.Error("transform error must propagate")
+	}
+	if err := res.Set("x", NewJsonResult(`{}`, "").GetData(&JsonKey{Path: "a", Tag: "required"})).Err(); err == nil {
+		t.Error("json data error must propagate")
+	}
+}
+
+func TestMergeJsonResult(t *testing.T) {
+	product := NewJsonResult(`{"data":{"id":1,"info":{"name":"a","price":1}}}`, "data")
+	stock := NewJsonResult(`{"data":{"info":{"price":2,"stock":5},"tags":["x"]}}`, "data")
+	merged := MergeJsonResult(product, stock, NewJsonResult(`{"data":{}}`, "none"))
+	if body := merged.Body(); body != `{"id":1,"info":{"name":"a","price":2,"stock":5},"tags":["x"]}` {
+		t.Errorf("merge wrong:%s", body)
+	}
+	err := NewRestClientError("1", "fail")
+	if !errors.Is(MergeJsonResult(product, NewJsonResultFromError(err)).Err(), err) {
+		t.Error("merge error must propagate")
+	}
+	if MergeJsonResult().Body() != "{}" {
+		t.Error("empty merge wrong")
+	}
+}
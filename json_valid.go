@@ -0,0 +1,223 @@
+package rest_client
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	zhTranslations "github.com/go-playground/validator/v10/translations/zh"
+	"strings"
+)
+
+// NewJsonValid 创建校验结构,字段名使用JSON名称
+// @param ctx 可以为nil,校验时透传
+func NewJsonValid(ctx context.Context) *JsonValid {
+	return &JsonValid{
+		valid:   newJsonValidate(),
+		Context: ctx,
+	}
+}
+
+// NewJsonValidFrom 使用外部定义的 validator 创建校验结构
+func NewJsonValidFrom(ctx context.Context, valid *validator.Validate) *JsonValid {
+	return &JsonValid{
+		valid:   valid,
+		Context: ctx,
+	}
+}
+
+// Validate 使用的 validator,可用于注册其他规则
+func (jv *JsonValid) Validate() *validator.Validate {
+	if jv.valid == nil {
+		jv.valid = newJsonValidate()
+	}
+	return jv.valid
+}
+
+// RegisterValidation 注册自定义校验TAG
+func (jv *JsonValid) RegisterValidation(tag string, fn validator.FuncCtx, callValidationEvenIfNull ...bool) error {
+	return jv.Validate().RegisterValidationCtx(tag, fn, callValidationEvenIfNull...)
+}
+
+// RegisterStructValidation 注册结构级校验
+// @param types 校验的结构,传入结构值
+func (jv *JsonValid) RegisterStructValidation(fn validator.StructLevelFuncCtx, types ...interface{}) *JsonValid {
+	jv.Validate().RegisterStructValidationCtx(fn, types...)
+	return jv
+}
+
+// WithLocale 设置错误信息语言,支持 zh 及 en
+func (jv *JsonValid) WithLocale(locale string) (*JsonValid, error) {
+	//翻译注册在各自的 validator 上,每次单独创建避免重复注册冲突
+	trans, ok := ut.New(en.New(), en.New(), zh.New()).GetTranslator(locale)
+	if !ok || trans.Locale() != locale {
+		return nil, NewRestClientError("20", "valid locale not support:"+locale)
+	}
+	var err error
+	switch locale {
+	case "zh":
+		err = zhTranslations.RegisterDefaultTranslations(jv.Validate(), trans)
+	default:
+		err = enTranslations.RegisterDefaultTranslations(jv.Validate(), trans)
+	}
+	if err != nil {
+		return nil, err
+	}
+	jv.trans = trans
+	return jv, nil
+}
+
+// RegisterTranslation 注册自定义TAG的错误信息,需先调用 WithLocale
+// @param text 错误信息,{0}为字段名,{1}为TAG参数
+func (jv *JsonValid) RegisterTranslation(tag string, text string) error {
+	if jv.trans == nil {
+		return NewRestClientError("20", "valid locale is not set")
+	}
+	return jv.Validate().RegisterTranslation(tag, jv.trans, func(trans ut.Translator) error {
+		return trans.Add(tag, text, true)
+	}, func(trans ut.Translator, fErr validator.FieldError) string {
+		msg, err := trans.T(tag, fErr.Field(), fErr.Param())
+		if err != nil {
+			return fErr.Error()
+		}
+		return msg
+	})
+}
+
+// with 未设置 validator 时使用默认的 validator
+func (jv *JsonValid) with(def *validator.Validate) *JsonValid {
+	if jv == nil {
+		return &JsonValid{valid: def}
+	}
+	if jv.valid != nil {
+		return jv
+	}
+	tmp := *jv
+	tmp.valid = def
+	return &tmp
+}
+
+// varValid 校验单个值
+func (jv *JsonValid) varValid(val interface{}, tag string) error {
+	if jv.Context == nil {
+		return jv.valid.Var(val, tag)
+	}
+	return jv.valid.VarCtx(jv.Context, val, tag)
+}
+
+// structValid 校验结构,失败的字段记录到 vErr,非结构体不做校验
+// @return 非校验失败的错误
+func (jv *JsonValid) structValid(vErr *ValidationError, path string, structPtr interface{}) error {
+	var err error
+	if jv.Context == nil {
+		err = jv.valid.Struct(structPtr)
+	} else {
+		err = jv.valid.StructCtx(jv.Context, structPtr)
+	}
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*validator.InvalidValidationError); ok {
+		return nil
+	}
+	fErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err
+	}
+	for _, fErr := range fErrs {
+		//去掉命名空间中的结构名
+		ns := fErr.Namespace()
+		if i := strings.Index(ns, "."); i >= 0 {
+			ns = ns[i+1:]
+		}
+		fPath := pathCreate(path, ns)
+		msg := jv.fieldMessage(fErr.Field(), fErr)
+		vErr.Fields = append(vErr.Fields, &ValidationFieldError{
+			Path:    fPath,
+			Field:   fErr.StructField(),
+			Tag:     fErr.Tag(),
+			Param:   fErr.Param(),
+			Value:   jv.redact(fPath, fErr.StructField(), fErr.Value()),
+			Message: msg,
+		})
+	}
+	return nil
+}
+
+// fieldMessage 校验错误信息,设置语言时使用翻译,否则为不含结构名的默认信息
+// @param name 字段名,单个值校验时 fErr 中的字段名为空,使用JSON名称
+func (jv *JsonValid) fieldMessage(name string, fErr validator.FieldError) string {
+	if jv.trans == nil {
+		return fmt.Sprintf("Field validation for '%s' failed on the '%s' tag", name, fErr.Tag())
+	}
+	if len(fErr.Field()) == 0 {
+		return name + fErr.Translate(jv.trans)
+	}
+	return fErr.Translate(jv.trans)
+}
+
+// appendVarError 记录单个值的校验错误
+func (jv *JsonValid) appendVarError(vErr *ValidationError, path string, field string, val interface{}, err error) {
+	fErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		vErr.Fields = append(vErr.Fields, &ValidationFieldError{Path: path, Field: field, Message: err.Error()})
+		return
+	}
+	name := path
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		name = path[i+1:]
+	}
+	for _, fErr := range fErrs {
+		msg := jv.fieldMessage(name, fErr)
+		vErr.Fields = append(vErr.Fields, &ValidationFieldError{
+			Path:    path,
+			Field:   field,
+			Tag:     fErr.Tag(),
+			Param:   fErr.Param(),
+			Value:   jv.redact(path, field, val),
+			Message: msg,
+		})
+	}
+}
+
+// WithRedact 设置校验错误中需隐藏值的字段,可为JSON路径或字段名,不传时隐藏全部值
+func (jv *JsonValid) WithRedact(fields ...string) *JsonValid {
+	if fields == nil {
+		fields = []string{"*"}
+	}
+	jv.redactFields = append(jv.redactFields, fields...)
+	return jv
+}
+
+// redact 校验错误中的值,需隐藏时返回 ******
+func (jv *JsonValid) redact(path string, field string, val interface{}) interface{} {
+	for _, tmp := range jv.redactFields {
+		if tmp == "*" || tmp == path || (len(field) > 0 && tmp == field) {
+			return "******"
+		}
+	}
+	if data, ok := val.(ToJsonData); ok {
+		//JsonData 使用原始值
+		if jData := data.ToJsonData(); jData != nil && jData.Result != nil {
+			return jData.Value()
+		}
+		return nil
+	}
+	return val
+}
+
+// validStruct 校验解析后的结构,非结构体不做校验
+func validStruct(valid *validator.Validate, structPtr interface{}, jsonValid []*JsonValid) error {
+	var jv *JsonValid
+	if len(jsonValid) > 0 {
+		jv = jsonValid[0]
+	}
+	vErr := &ValidationError{}
+	if err := jv.with(valid).structValid(vErr, "", structPtr); err != nil {
+		return err
+	}
+	return vErr.orNil()
+}
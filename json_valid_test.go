@@ -0,0 +1,80 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/go-playground/validator/v10"
+	"strings"
+	"testing"
+)
+
+type testValidOrder struct {
+	Sku   string `json:"sku" validate:"required,sku"`
+	Price int    `json:"price" validate:"gt=0"`
+	Min   int    `json:"min"`
+	Max   int    `json:"max"`
+}
+
+func TestJsonValid(t *testing.T) {
+	jv := NewJsonValid(context.Background())
+	if err := jv.RegisterValidation("sku", func(_ context.Context, fl validator.FieldLevel) bool {
+		return strings.HasPrefix(fl.Field().String(), "SKU")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	jv.RegisterStructValidation(func(_ context.Context, sl validator.StructLevel) {
+		order := sl.Current().Interface().(testValidOrder)
+		if order.Min > order.Max {
+			sl.ReportError(order.Min, "min", "Min", "ltefield", "max")
+		}
+	}, testValidOrder{})
+
+	var order testValidOrder
+	if err := NewJsonResult(`{"sku":"SKU1","price":1,"min":1,"max":2}`, "").GetStruct("", &order, jv); err != nil {
+		t.Fatal(err)
+	}
+	err := NewJsonResult(`{"data":{"sku":"A1","price":1}}`, "").GetStruct("data", &testValidOrder{}, jv)
+	if err == nil || !strings.Contains(err.Error(), "path:data.sku ") || !strings.Contains(err.Error(), "'sku' tag") {
+		t.Errorf("custom validation wrong:%v", err)
+	}
+	err = NewJsonResult(`{"sku":"SKU1","price":1,"min":3,"max":2}`, "").GetStruct("", &testValidOrder{}, jv)
+	if err == nil || !strings.Contains(err.Error(), "path:min ") {
+		t.Errorf("struct validation wrong:%v", err)
+	}
+}
+
+func TestJsonValidLocale(t *testing.T) {
+	for locale, msg := range map[string]string{
+		"zh": "price必须大于0",
+		"en": "price must be greater than 0",
+	} {
+		jv, err := NewJsonValid(nil).WithLocale(locale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = NewJsonResult(`{"sku":"SKU1","price":0}`, "").GetStruct("", &struct {
+			Price int `json:"price" validate:"gt=0"`
+		}{}, jv)
+		if err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("%s message wrong:%v", locale, err)
+		}
+	}
+	jv, _ := NewJsonValid(nil).WithLocale("zh")
+	if err := jv.RegisterValidation("sku", func(_ context.Context, fl validator.FieldLevel) bool {
+		return strings.HasPrefix(fl.Field().String(), "SKU")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := jv.RegisterTranslation("sku", "{0}必须以SKU开头"); err != nil {
+		t.Fatal(err)
+	}
+	data := NewJsonResult(`{"sku":"A1"}`, "").GetData(&JsonKey{Path: "sku", Tag: "sku", JsonValid: jv})
+	if data.Err() == nil || !strings.Contains(data.Err().Error(), "必须以SKU开头") {
+		t.Errorf("custom translation wrong:%v", data.Err())
+	}
+	if _, err := NewJsonValid(nil).WithLocale("fr"); err == nil {
+		t.Error("locale not support")
+	}
+	if err := NewJsonValid(nil).RegisterTranslation("sku", "{0}"); err == nil {
+		t.Error("translation need locale")
+	}
+}
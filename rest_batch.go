@@ -0,0 +1,161 @@
+package rest_client
+
+import (
+	"context"
+	"sync"
+)
+
+// RestBatchCall 批量请求中的单个调用
+type RestBatchCall struct {
+	Client *RestClient
+	Key    interface{} //接口KEY,参见 RestKey
+	Param  interface{}
+}
+
+// Call 创建一个批量请求调用
+func (client *RestClient) Call(key interface{}, param interface{}) *RestBatchCall {
+	return &RestBatchCall{
+		Client: client,
+		Key:    key,
+		Param:  param,
+	}
+}
+
+// RestBatchMode 批量请求结果模式
+type RestBatchMode int
+
+const (
+	RestBatchAll     RestBatchMode = iota //全部成功才算成功,任一失败 Err 返回错误
+	RestBatchPartial                      //允许部分失败,各自结果通过 Result 获取
+)
+
+// RestBatch 批量请求配置
+type RestBatch struct {
+	Concurrency  int           //最大并发数,0表示不限制
+	Mode         RestBatchMode //结果模式
+	CancelOnFail bool          //任一调用失败时取消未完成的调用
+}
+
+// RestBatchResult 批量请求结果,按调用顺序保存
+type RestBatchResult struct {
+	results []*RestResult
+	failed  []int
+	err     error
+}
+
+// Len 调用数量
+func (batch *RestBatchResult) Len() int {
+	return len(batch.results)
+}
+
+// Result 获取指定下标调用的结果
+func (batch *RestBatchResult) Result(index int) *RestResult {
+	return batch.results[index]
+}
+
+// Results 获取全部调用结果
+func (batch *RestBatchResult) Results() []*RestResult {
+	return batch.results
+}
+
+// Failed 失败调用的下标,按失败先后排序
+func (batch *RestBatchResult) Failed() []int {
+	return batch.failed
+}
+
+// Err 批量请求错误, RestBatchAll 模式下返回第一个失败的错误
+func (batch *RestBatchResult) Err() error {
+	return batch.err
+}
+
+// Cancel 关闭全部结果的返回内容,放弃未读取的结果时调用
+// 每个调用使用单独的上下文,结果读取完或关闭时自动结束,无需调用此函数
+func (batch *RestBatchResult) Cancel() {
+	for _, res := range batch.results {
+		if res != nil {
+			_ = res.Close()
+		}
+	}
+}
+
+// DoBatch 批量执行请求,等待全部完成后返回
+// @param batch 可不传,默认不限制并发且全部成功才算成功
+func DoBatch(ctx context.Context, calls []*RestBatchCall, batch ...*RestBatch) *RestBatchResult {
+	ctx = withRestCaller(ctx, 0)
+	setBatch := &RestBatch{}
+	if batch != nil && batch[0] != nil {
+		setBatch = batch[0]
+	}
+	//batchCtx 只用于停止发起新的调用,每个调用使用单独的上下文,取消时不影响已完成调用的返回内容
+	batchCtx, batchCancel := context.WithCancel(ctx)
+	defer batchCancel()
+	result := &RestBatchResult{
+		results: make([]*RestResult, len(calls)),
+	}
+	var sem chan struct{}
+	if setBatch.Concurrency > 0 {
+		sem = make(chan struct{}, setBatch.Concurrency)
+	}
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	pending := make(map[int]context.CancelFunc)
+	finish := func(index int, res *RestResult) {
+		lock.Lock()
+		defer lock.Unlock()
+		if callCancel, ok := pending[index]; ok {
+			delete(pending, index)
+			res.onClose(callCancel)
+		}
+		result.results[index] = res
+		if res.Err() == nil {
+			return
+		}
+		result.failed = append(result.failed, index)
+		if result.err == nil && setBatch.Mode == RestBatchAll {
+			result.err = res.Err()
+		}
+		if setBatch.CancelOnFail {
+			batchCancel()
+			for _, callCancel := range pending {
+				callCancel()
+			}
+		}
+	}
+	for i, call := range calls {
+		acquired := false
+		if sem != nil {
+			select {
+			case sem <- struct{}{}:
+				acquired = true
+			case <-batchCtx.Done():
+			}
+		}
+		lock.Lock()
+		err := batchCtx.Err()
+		var callCtx context.Context
+		if err == nil {
+			var callCancel context.CancelFunc
+			callCtx, callCancel = context.WithCancel(ctx)
+			pending[i] = callCancel
+		}
+		lock.Unlock()
+		if err != nil {
+			if acquired {
+				<-sem
+			}
+			finish(i, NewRestResultFromError(err, nil))
+			continue
+		}
+		wg.Add(1)
+		go func(index int, call *RestBatchCall) {
+			defer wg.Done()
+			res := <-call.Client.Do(callCtx, call.Key, call.Param)
+			finish(index, res)
+			if sem != nil {
+				<-sem
+			}
+		}(i, call)
+	}
+	wg.Wait()
+	return result
+}
@@ -0,0 +1,105 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDoBatch(t *testing.T) {
+	var running, maxRunning int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"}}`))
+	}))
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	api := client.NewApi(&testDome1{})
+	batch := DoBatch(context.Background(), []*RestBatchCall{
+		api.Call(test1, nil),
+		api.Call(test2, nil),
+		api.Call(test1, nil),
+		api.Call(100, nil),
+	}, &RestBatch{
+		Concurrency: 2,
+		Mode:        RestBatchPartial,
+	})
+	defer batch.Cancel()
+	if batch.Err() != nil {
+		t.Error("partial mode not return error")
+	}
+	if batch.Len() != 4 || len(batch.Failed()) != 1 || batch.Failed()[0] != 3 {
+		t.Error("batch failed index wrong")
+	}
+	for i := 0; i < 3; i++ {
+		if err := batch.Result(i).JsonResult().Err(); err != nil {
+			t.Error(err)
+		}
+	}
+	if atomic.LoadInt32(&maxRunning) > 2 {
+		t.Error("batch concurrency not limited")
+	}
+
+	allBatch := DoBatch(context.Background(), []*RestBatchCall{
+		api.Call(100, nil),
+		api.Call(test1, nil),
+	}, &RestBatch{
+		Concurrency:  1,
+		CancelOnFail: true,
+	})
+	defer allBatch.Cancel()
+	if allBatch.Err() == nil {
+		t.Error("all mode must return error")
+	}
+	if allBatch.Result(1).Err() == nil {
+		t.Error("call after fail must cancel")
+	}
+}
+
+func TestDoBatchCancelOnFailKeepsFinished(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"code":"200",`))
+		w.(http.Flusher).Flush()
+		<-block
+		_, _ = w.Write([]byte(`"state":"ok"}}`))
+	}))
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	api := client.NewApi(&testDome1{})
+	batch := DoBatch(context.Background(), []*RestBatchCall{
+		api.Call(test1, nil),
+		api.Call(100, nil),
+		api.Call(test1, nil),
+	}, &RestBatch{
+		Concurrency:  1,
+		Mode:         RestBatchPartial,
+		CancelOnFail: true,
+	})
+	close(block)
+	if len(batch.Failed()) != 2 || batch.Result(2).Err() == nil {
+		t.Errorf("call after fail must cancel:%v", batch.Failed())
+	}
+	if err := batch.Result(0).JsonResult().Err(); err != nil {
+		t.Errorf("finished call must be readable after cancel:%v", err)
+	}
+}
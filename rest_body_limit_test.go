@@ -0,0 +1,85 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testTruncateEvent struct {
+	*AppRestEvent
+	limit int64
+}
+
+func (event *testTruncateEvent) ResponseTruncate(limit int64) {
+	event.limit = limit
+}
+
+func TestRestResultMaxBodySize(t *testing.T) {
+	body := `{"result":{"code":"200","state":"ok","data":"` + strings.Repeat("a", 1000) + `"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	var event *testTruncateEvent
+	var response []byte
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:        "test111",
+		AppUrl:      server.URL,
+		MaxBodySize: 100,
+		EventCreate: func(_ context.Context) RestEvent {
+			event = &testTruncateEvent{
+				AppRestEvent: NewAppRestEvent(func(_ string, _ string, _ int, _ map[string][]string, _ []byte, res []byte, _ error) {
+					response = res
+				}),
+			}
+			return event
+		},
+	})
+	api := client.NewApi(&testDome1{})
+	err := (<-api.Do(context.Background(), test1, nil)).JsonResult().Err()
+	if lErr, ok := err.(*RestBodyTooLargeError); !ok || lErr.Limit != 100 {
+		t.Fatalf("body too large error wrong:%v", err)
+	}
+	if event.limit != 100 || len(response) != 100 {
+		t.Errorf("truncate event wrong:%d %d", event.limit, len(response))
+	}
+
+	client.SetRestConfig(&AppRestConfig{
+		Name:        "test111",
+		AppUrl:      server.URL,
+		MaxBodySize: 100,
+		Builds: map[string]*RestBuildConfig{
+			"0": {MaxBodySize: int64(len(body))},
+		},
+	})
+	if err := (<-api.Do(context.Background(), test1, nil)).JsonResult().Err(); err != nil {
+		t.Errorf("build max body size must override:%v", err)
+	}
+}
+
+func TestAppRestEventMaxBuffer(t *testing.T) {
+	var response []byte
+	event := NewAppRestEvent(func(_ string, _ string, _ int, _ map[string][]string, _ []byte, res []byte, _ error) {
+		response = res
+	}).WithMaxBuffer(5)
+	event.ResponseRead([]byte("abc"))
+	event.ResponseRead([]byte("def"))
+	event.ResponseRead([]byte("ghi"))
+	event.ResponseFinish(nil)
+	if string(response) != "abcde" || !event.Truncated() {
+		t.Errorf("event buffer wrong:%s", response)
+	}
+	event = NewAppRestEvent(func(_ string, _ string, _ int, _ map[string][]string, _ []byte, res []byte, _ error) {
+		response = res
+	})
+	event.ResponseRead([]byte("abcdef"))
+	event.WithMaxBuffer(3).ResponseRead([]byte("ghi"))
+	event.ResponseFinish(nil)
+	if string(response) != "abcdef" || !event.Truncated() {
+		t.Errorf("lowered event buffer wrong:%s", response)
+	}
+}
@@ -0,0 +1,66 @@
+package rest_client
+
+import (
+	"context"
+	"time"
+)
+
+// RestBuildsConfig 服务配置可选实现,提供返回内容最大字节数及按接口KEY的覆盖配置
+// 内置的 RestBuild 按 覆盖配置、接口配置、服务配置 的顺序确定超时时间及返回内容最大字节数
+type RestBuildsConfig interface {
+	GetMaxBodySize() int64                  //返回内容最大字节数,0不限制
+	GetBuilds() map[string]*RestBuildConfig //按接口KEY覆盖接口配置,可以为nil
+}
+
+// restBuildConfig 接口KEY对应的覆盖配置,自定义整数类型KEY可按名称或数值配置,未配置时返回nil
+func restBuildConfig(builds map[string]*RestBuildConfig, key RestKey) *RestBuildConfig {
+	for _, name := range key.names() {
+		if override := builds[name]; override != nil {
+			return override
+		}
+	}
+	return nil
+}
+
+// restBuildLimit 本次请求的超时时间及返回内容最大字节数
+// @param timeout 接口配置的超时时间
+// @param maxBodySize 接口配置的返回内容最大字节数,为0时使用服务配置
+func restBuildLimit(config RestConfig, key RestKey, timeout time.Duration, maxBodySize int64) (time.Duration, int64) {
+	bConfig, ok := config.(RestBuildsConfig)
+	if !ok {
+		return timeout, maxBodySize
+	}
+	if maxBodySize <= 0 {
+		maxBodySize = bConfig.GetMaxBodySize()
+	}
+	if override := restBuildConfig(bConfig.GetBuilds(), key); override != nil {
+		if override.Timeout > 0 {
+			timeout = time.Duration(override.Timeout)
+		}
+		if override.MaxBodySize > 0 {
+			maxBodySize = override.MaxBodySize
+		}
+	}
+	return timeout, maxBodySize
+}
+
+// restBuildEvent 创建本次请求的事件,并回调接口KEY及并发隔离排队信息
+// 配置未实现 RestEventConfig 时使用 RestEventNoop
+func restBuildEvent(ctx context.Context, config RestConfig, key RestKey) RestEvent {
+	var event RestEvent
+	if eConfig, ok := config.(RestEventConfig); ok {
+		event = eConfig.NewEvent(ctx)
+	}
+	if event == nil {
+		event = &RestEventNoop{}
+	}
+	if kEvent, ok := event.(RestKeyEvent); ok {
+		kEvent.RequestKey(key)
+	}
+	if bEvent, ok := event.(RestBulkheadEvent); ok {
+		if name, wait, find := RestBulkheadWait(ctx); find {
+			bEvent.BulkheadWait(name, wait, nil)
+		}
+	}
+	return event
+}
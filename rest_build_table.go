@@ -0,0 +1,158 @@
+package rest_client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// restBuildTable 注册时生成的接口表
+type restBuildTable struct {
+	configName string
+	keyType    reflect.Type
+	builds     map[int]RestBuild
+	named      map[string]RestBuild
+}
+
+// newRestBuildTable 生成接口表
+func newRestBuildTable(ctx context.Context, api RestApi) (*restBuildTable, error) {
+	configName, err := api.ConfigName(ctx)
+	if err != nil {
+		return nil, err
+	}
+	builds, err := api.ConfigBuilds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	table := &restBuildTable{
+		configName: configName,
+		builds:     builds,
+	}
+	if nApi, ok := api.(RestNamedApi); ok {
+		if table.named, err = nApi.NamedConfigBuilds(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if tApi, ok := api.(RestKeyTypeApi); ok {
+		table.keyType = tApi.KeyType()
+	}
+	return table, nil
+}
+
+// endpoints 接口表中的全部接口信息
+func (table *restBuildTable) endpoints() []*RestEndpoint {
+	var endpoints []*RestEndpoint
+	add := func(key string, build RestBuild) {
+		endpoint := &RestEndpoint{}
+		if info, ok := build.(RestBuildInfo); ok {
+			tmp := *info.BuildInfo()
+			endpoint = &tmp
+		}
+		endpoint.ConfigName = table.configName
+		endpoint.Key = key
+		endpoints = append(endpoints, endpoint)
+	}
+	for id, build := range table.builds {
+		add(restKeyName(table.keyType, id), build)
+	}
+	for name, build := range table.named {
+		add(name, build)
+	}
+	return endpoints
+}
+
+// RestBuildOverride 接口可选实现,注册接口表后按上下文调整单次请求使用的接口配置
+// 返回的 RestBuild 不要修改 build 本身,需要修改时复制一份
+type RestBuildOverride interface {
+	OverrideBuild(ctx context.Context, key RestKey, build RestBuild) (RestBuild, error)
+}
+
+// RestEndpoint 接口信息,用于健康检查及文档生成
+type RestEndpoint struct {
+	ConfigName string
+	Key        string
+	HttpMethod string
+	Path       string
+	Method     string
+	Timeout    time.Duration
+}
+
+// RestBuildInfo RestBuild 可选实现,用于获取接口信息
+type RestBuildInfo interface {
+	BuildInfo() *RestEndpoint
+}
+
+// RegisterApi 注册接口表, ConfigBuilds 只在注册时调用一次,之后同类型的 RestApi 请求不再重新生成
+// ConfigBuilds 依赖上下文时不要注册,或通过 RestBuildOverride 调整
+func (c *RestClientManager) RegisterApi(api RestApi) error {
+	table, err := newRestBuildTable(context.Background(), api)
+	if err != nil {
+		return err
+	}
+	c.tableLock.Lock()
+	defer c.tableLock.Unlock()
+	if c.tables == nil {
+		c.tables = make(map[reflect.Type]*restBuildTable)
+	}
+	c.tables[reflect.TypeOf(api)] = table
+	return nil
+}
+
+// getTable 获取已注册的接口表,未注册时返回nil
+func (c *RestClientManager) getTable(api RestApi) *restBuildTable {
+	c.tableLock.RLock()
+	defer c.tableLock.RUnlock()
+	return c.tables[reflect.TypeOf(api)]
+}
+
+// Endpoints 已注册的全部接口信息
+func (c *RestClientManager) Endpoints() []*RestEndpoint {
+	c.tableLock.RLock()
+	defer c.tableLock.RUnlock()
+	var endpoints []*RestEndpoint
+	for _, table := range c.tables {
+		endpoints = append(endpoints, table.endpoints()...)
+	}
+	sortRestEndpoints(endpoints)
+	return endpoints
+}
+
+// Endpoints 当前接口的全部接口信息
+func (client *RestClient) Endpoints(ctx context.Context) ([]*RestEndpoint, error) {
+	table := client.manager.getTable(client.Api)
+	if table == nil {
+		var err error
+		if table, err = newRestBuildTable(ctx, client.Api); err != nil {
+			return nil, err
+		}
+	}
+	endpoints := table.endpoints()
+	sortRestEndpoints(endpoints)
+	return endpoints, nil
+}
+
+// restKeyName int KEY的名称, KEY类型实现 fmt.Stringer 时使用其名称
+func restKeyName(keyType reflect.Type, id int) string {
+	if keyType != nil {
+		switch keyType.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			val := reflect.New(keyType).Elem()
+			val.SetInt(int64(id))
+			if str, ok := val.Interface().(fmt.Stringer); ok {
+				return str.String()
+			}
+		}
+	}
+	return fmt.Sprint(id)
+}
+
+func sortRestEndpoints(endpoints []*RestEndpoint) {
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].ConfigName != endpoints[j].ConfigName {
+			return endpoints[i].ConfigName < endpoints[j].ConfigName
+		}
+		return endpoints[i].Key < endpoints[j].Key
+	})
+}
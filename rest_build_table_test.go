@@ -0,0 +1,94 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testTableApi struct {
+	testKeyApi
+	calls    *int
+	override bool
+}
+
+func (res *testTableApi) ConfigBuilds(ctx context.Context) (map[int]RestBuild, error) {
+	*res.calls++
+	return res.testKeyApi.ConfigBuilds(ctx)
+}
+
+func (res *testTableApi) OverrideBuild(_ context.Context, _ RestKey, build RestBuild) (RestBuild, error) {
+	if !res.override {
+		return build, nil
+	}
+	tmp := *build.(*AppRestBuild)
+	tmp.Path = "/override"
+	return &tmp, nil
+}
+
+func TestRegisterApi(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"}}`))
+	}))
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	calls := 0
+	if err := client.RegisterApi(&testTableApi{calls: &calls}); err != nil {
+		t.Fatal(err)
+	}
+	api := client.NewApi(&testTableApi{calls: &calls})
+	for i := 0; i < 3; i++ {
+		if err := (<-api.Do(context.Background(), testKeyDetail, nil)).JsonResult().Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("config builds call %d times", calls)
+	}
+	if path != "/detail" {
+		t.Error("path wrong")
+	}
+	api = client.NewApi(&testTableApi{calls: &calls, override: true})
+	if err := (<-api.Do(context.Background(), testKeyDetail, nil)).JsonResult().Err(); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/override" {
+		t.Error("override path wrong")
+	}
+	if err := (<-api.Do(context.Background(), "detail", nil)).JsonResult().Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEndpoints(t *testing.T) {
+	client := NewRestClientManager()
+	calls := 0
+	if err := client.RegisterApi(&testTableApi{calls: &calls}); err != nil {
+		t.Fatal(err)
+	}
+	endpoints := client.Endpoints()
+	if len(endpoints) != 2 {
+		t.Fatalf("endpoints len %d", len(endpoints))
+	}
+	if endpoints[0].Key != "detail" || endpoints[1].Key != "testKeyDetail" {
+		t.Error("endpoint key wrong")
+	}
+	if endpoints[1].ConfigName != "test111" || endpoints[1].Path != "/detail" || endpoints[1].HttpMethod != http.MethodGet {
+		t.Error("endpoint info wrong")
+	}
+	apiEndpoints, err := client.NewApi(&testDome1{}).Endpoints(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(apiEndpoints) != 2 || apiEndpoints[0].Key != "0" || apiEndpoints[0].Timeout != 2*time.Second {
+		t.Error("not register api endpoints wrong")
+	}
+}
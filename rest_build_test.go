@@ -0,0 +1,67 @@
+package rest_client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRestBuildLimit(t *testing.T) {
+	key, _ := NewRestKey(test1)
+	config := &SoapRestConfig{
+		MaxBodySize: 100,
+		Builds: map[string]*RestBuildConfig{
+			"0": {Timeout: RestDuration(3 * time.Second)},
+		},
+	}
+	timeout, maxBodySize := restBuildLimit(config, key, time.Second, 0)
+	if timeout != 3*time.Second || maxBodySize != 100 {
+		t.Error("config limit wrong")
+	}
+	config.Builds["0"].MaxBodySize = 10
+	if _, maxBodySize = restBuildLimit(config, key, time.Second, 50); maxBodySize != 10 {
+		t.Error("override max body size wrong")
+	}
+	config.Builds = nil
+	timeout, maxBodySize = restBuildLimit(config, key, time.Second, 50)
+	if timeout != time.Second || maxBodySize != 50 {
+		t.Error("build limit wrong")
+	}
+}
+
+type testBuildConfig struct{}
+
+func (config *testBuildConfig) GetName() string {
+	return "test111"
+}
+
+type testBuildEvent struct {
+	RestEventNoop
+	key  string
+	wait bool
+}
+
+func (event *testBuildEvent) RequestKey(key RestKey) {
+	event.key = key.String()
+}
+
+func (event *testBuildEvent) BulkheadWait(_ string, _ time.Duration, _ error) {
+	event.wait = true
+}
+
+func TestRestBuildEvent(t *testing.T) {
+	key, _ := NewRestKey("detail")
+	event := &testBuildEvent{}
+	config := &AppRestConfig{
+		EventCreate: func(_ context.Context) RestEvent {
+			return event
+		},
+	}
+	ctx := context.WithValue(context.Background(), restBulkheadKey{}, &restBulkheadWait{name: "test111"})
+	if restBuildEvent(ctx, config, key) != event || event.key != "detail" || !event.wait {
+		t.Error("build event wrong")
+	}
+	if _, ok := restBuildEvent(ctx, &testBuildConfig{}, key).(*RestEventNoop); !ok {
+		t.Error("config without event must use noop")
+	}
+}
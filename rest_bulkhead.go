@@ -0,0 +1,157 @@
+package rest_client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RestBulkhead 按配置隔离的并发限制,防止单个慢服务占满公共连接池
+type RestBulkhead struct {
+	maxQueue     int32
+	queueTimeout time.Duration
+	sem          chan struct{}
+	queue        int32
+}
+
+// NewRestBulkhead 创建并发隔离
+// @param maxConcurrent 最大同时请求数
+// @param maxQueue 最大排队数,0表示不排队,超过直接拒绝
+// @param queueTimeout 最长排队时间,0表示只受context限制
+func NewRestBulkhead(maxConcurrent int, maxQueue int, queueTimeout time.Duration) *RestBulkhead {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	if maxQueue < 0 {
+		maxQueue = 0
+	}
+	return &RestBulkhead{
+		maxQueue:     int32(maxQueue),
+		queueTimeout: queueTimeout,
+		sem:          make(chan struct{}, maxConcurrent),
+	}
+}
+
+// Acquire 获取执行位置,返回排队等待时间,成功后必须调用 Release
+func (bulkhead *RestBulkhead) Acquire(ctx context.Context) (time.Duration, error) {
+	select {
+	case bulkhead.sem <- struct{}{}:
+		return 0, nil
+	default:
+	}
+	if atomic.AddInt32(&bulkhead.queue, 1) > bulkhead.maxQueue {
+		atomic.AddInt32(&bulkhead.queue, -1)
+		return 0, NewRestClientError("4", "bulkhead queue is full")
+	}
+	defer atomic.AddInt32(&bulkhead.queue, -1)
+	start := time.Now()
+	var timeout <-chan time.Time
+	if bulkhead.queueTimeout > 0 {
+		timer := time.NewTimer(bulkhead.queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case bulkhead.sem <- struct{}{}:
+		return time.Since(start), nil
+	case <-timeout:
+		return time.Since(start), NewRestClientError("5", "bulkhead queue wait timeout")
+	case <-ctx.Done():
+		return time.Since(start), ctx.Err()
+	}
+}
+
+// TryAcquire 不等待获取执行位置,成功后必须调用 Release
+func (bulkhead *RestBulkhead) TryAcquire() bool {
+	select {
+	case bulkhead.sem <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Release 释放执行位置
+func (bulkhead *RestBulkhead) Release() {
+	<-bulkhead.sem
+}
+
+// InFlight 当前执行中请求数
+func (bulkhead *RestBulkhead) InFlight() int {
+	return len(bulkhead.sem)
+}
+
+// Queued 当前排队中请求数
+func (bulkhead *RestBulkhead) Queued() int {
+	return int(atomic.LoadInt32(&bulkhead.queue))
+}
+
+// RestBulkheadEvent 事件可选实现,用于接收并发隔离的排队信息
+type RestBulkheadEvent interface {
+	BulkheadWait(name string, wait time.Duration, err error) //排队结束时回调,被拒绝时err不为nil
+}
+
+type restBulkheadKey struct{}
+
+type restBulkheadWait struct {
+	name     string
+	wait     time.Duration
+	bulkhead *RestBulkhead
+}
+
+// RestBulkheadWait 从上下文中获取本次请求的排队信息,供 RestBuild 回调事件使用
+func RestBulkheadWait(ctx context.Context) (string, time.Duration, bool) {
+	if wait, ok := ctx.Value(restBulkheadKey{}).(*restBulkheadWait); ok {
+		return wait.name, wait.wait, true
+	}
+	return "", 0, false
+}
+
+// restBulkheadFrom 上下文中本次请求使用的并发隔离,未设置时返回nil
+func restBulkheadFrom(ctx context.Context) *RestBulkhead {
+	if wait, ok := ctx.Value(restBulkheadKey{}).(*restBulkheadWait); ok {
+		return wait.bulkhead
+	}
+	return nil
+}
+
+// RestEventConfig 配置可选实现,用于在 RestBuild 之外创建事件
+type RestEventConfig interface {
+	NewEvent(ctx context.Context) RestEvent
+}
+
+// acquireBulkhead 获取当前配置的执行位置,未设置并发隔离时直接通过
+// 返回的 release 可重复调用,只释放一次
+func (client *RestClient) acquireBulkhead(ctx context.Context) (context.Context, func(), *RestResult) {
+	configName, err := client.Api.ConfigName(ctx)
+	if err != nil {
+		return ctx, nil, NewRestResultFromError(err, nil)
+	}
+	bulkhead := client.manager.getBulkhead(configName)
+	if bulkhead == nil {
+		return ctx, func() {}, nil
+	}
+	wait, err := bulkhead.Acquire(ctx)
+	if err != nil {
+		var event RestEvent
+		if config, cErr := client.GetConfig(ctx); cErr == nil {
+			if eConfig, ok := config.(RestEventConfig); ok {
+				event = eConfig.NewEvent(ctx)
+			}
+		}
+		if bEvent, ok := event.(RestBulkheadEvent); ok {
+			bEvent.BulkheadWait(configName, wait, err)
+		}
+		return ctx, nil, NewRestResultFromError(err, event)
+	}
+	ctx = context.WithValue(ctx, restBulkheadKey{}, &restBulkheadWait{
+		name:     configName,
+		wait:     wait,
+		bulkhead: bulkhead,
+	})
+	var once sync.Once
+	return ctx, func() {
+		once.Do(bulkhead.Release)
+	}, nil
+}
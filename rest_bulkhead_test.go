@@ -0,0 +1,177 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRestBulkhead(t *testing.T) {
+	bulkhead := NewRestBulkhead(1, 1, 20*time.Millisecond)
+	if _, err := bulkhead.Acquire(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if bulkhead.InFlight() != 1 {
+		t.Error("in flight count wrong")
+	}
+	wait, err := bulkhead.Acquire(context.Background())
+	if err == nil {
+		t.Fatal("queue wait must timeout")
+	}
+	if wait < 20*time.Millisecond {
+		t.Error("queue wait time wrong")
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := bulkhead.Acquire(context.Background()); err != nil {
+			t.Error(err)
+		}
+	}()
+	for bulkhead.Queued() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := bulkhead.Acquire(context.Background()); err == nil {
+		t.Error("queue is full")
+	} else if rErr, ok := err.(*RestClientError); !ok || rErr.Code != "4" {
+		t.Error(err)
+	}
+	bulkhead.Release()
+	<-done
+	bulkhead.Release()
+	if bulkhead.InFlight() != 0 {
+		t.Error("release wrong")
+	}
+}
+
+type testBulkheadEvent struct {
+	RestEventNoop
+	mu    sync.Mutex
+	waits []error
+}
+
+func (event *testBulkheadEvent) BulkheadWait(_ string, _ time.Duration, err error) {
+	event.mu.Lock()
+	defer event.mu.Unlock()
+	event.waits = append(event.waits, err)
+}
+
+func TestRestClientBulkhead(t *testing.T) {
+	start := make(chan struct{})
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		start <- struct{}{}
+		<-unblock
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"}}`))
+	}))
+	defer server.Close()
+	event := &testBulkheadEvent{}
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+		EventCreate: func(_ context.Context) RestEvent {
+			return event
+		},
+	})
+	client.SetTransport("test111", NewRestTransport())
+	client.SetBulkhead("test111", NewRestBulkhead(1, 0, 0))
+	api := client.NewApi(&testDome1{})
+	if api.GetConfigTransport("test111") == api.GetTransport() {
+		t.Error("config transport not set")
+	}
+	first := api.Do(context.Background(), test1, nil)
+	<-start
+	second := <-api.Do(context.Background(), test1, nil)
+	if second.Err() == nil {
+		t.Error("bulkhead must reject")
+	}
+	close(unblock)
+	if res := <-first; res.Err() != nil {
+		t.Error(res.Err())
+	}
+	event.mu.Lock()
+	defer event.mu.Unlock()
+	if len(event.waits) != 2 || event.waits[0] != nil || event.waits[1] == nil {
+		t.Error("bulkhead event wrong")
+	}
+}
+
+func TestRestClientBulkheadBodyHold(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"code":"200",`))
+		w.(http.Flusher).Flush()
+		<-unblock
+		_, _ = w.Write([]byte(`"state":"ok"}}`))
+	}))
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	bulkhead := NewRestBulkhead(1, 0, 0)
+	client.SetBulkhead("test111", bulkhead)
+	api := client.NewApi(&testDome1{})
+	res := <-api.Do(context.Background(), test1, nil)
+	if res.Err() != nil {
+		t.Fatal(res.Err())
+	}
+	if bulkhead.InFlight() != 1 {
+		t.Error("bulkhead slot must be held while body is unread")
+	}
+	close(unblock)
+	if jRes := res.JsonResult(); jRes.Err() != nil {
+		t.Error(jRes.Err())
+	}
+	if bulkhead.InFlight() != 0 {
+		t.Error("bulkhead slot must be released after body is read")
+	}
+	res = <-api.Do(context.Background(), test1, nil)
+	if res.Err() != nil {
+		t.Fatal(res.Err())
+	}
+	if bulkhead.InFlight() != 1 {
+		t.Error("bulkhead slot must be held while body is unread")
+	}
+	res.Close()
+	if bulkhead.InFlight() != 0 {
+		t.Error("bulkhead slot must be released after close")
+	}
+}
+
+func TestRestClientBulkheadAbandon(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"}}`))
+	}))
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	bulkhead := NewRestBulkhead(1, 0, 0)
+	client.SetBulkhead("test111", bulkhead)
+	api := client.NewApi(&testDome1{})
+	for i := 0; i < 3; i++ {
+		res := <-api.Do(context.Background(), test1, nil)
+		if res.Err() != nil {
+			t.Fatal(res.Err())
+		}
+		if _, header := res.Header(); header == nil {
+			t.Fatal("header not find")
+		}
+		for j := 0; bulkhead.InFlight() != 0 && j < 50; j++ {
+			runtime.GC()
+			time.Sleep(10 * time.Millisecond)
+		}
+		if bulkhead.InFlight() != 0 {
+			t.Fatal("abandoned result must release bulkhead slot")
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"runtime"
 )
 
 // RestClientError  错误信息
@@ -52,8 +53,9 @@ func NewRestEventNoop() *RestEventNoop {
 
 //RestRequestReader 对请求io.Reader封装,用于读取内容时事件回调
 type RestRequestReader struct {
-	reader io.Reader
-	event  RestEvent
+	reader  io.Reader
+	event   RestEvent
+	secrets []string
 }
 
 func NewRestRequestReader(reader io.Reader, event RestEvent) *RestRequestReader {
@@ -62,13 +64,20 @@ func NewRestRequestReader(reader io.Reader, event RestEvent) *RestRequestReader
 		event:  event,
 	}
 }
+
+//WithRedact 设置回调事件前需脱敏的密钥
+func (read *RestRequestReader) WithRedact(secrets ...string) *RestRequestReader {
+	read.secrets = append(read.secrets, secrets...)
+	return read
+}
+
 func (read *RestRequestReader) Read(p []byte) (int, error) {
 	if read.reader == nil {
 		return 0, NewRestClientError("10", "request reader is empty")
 	}
 	n, err := read.reader.Read(p)
 	if read.event != nil && n > 0 {
-		read.event.RequestRead(p[0:n])
+		read.event.RequestRead(restSecretRedact(p[0:n], read.secrets))
 	}
 	return n, err
 }
@@ -82,6 +91,11 @@ type RestJsonResult interface {
 	CheckJsonResult(res string) error
 }
 
+//RestXmlResult RestBuild 可选实现,检测XML返回内容是否正常
+type RestXmlResult interface {
+	CheckXmlResult(res *XmlResult) error
+}
+
 // RestConfig 执行请求
 type RestConfig interface {
 	GetName() string
@@ -101,14 +115,22 @@ type RestTokenApi interface {
 
 //RestClient 请求
 type RestClient struct {
-	Api       RestApi
-	config    map[string]RestConfig
-	transport *http.Transport
+	Api     RestApi
+	manager *RestClientManager
 }
 
 //GetTransport 公共的Transport
 func (client *RestClient) GetTransport() *http.Transport {
-	return client.transport
+	return client.manager.load().transport
+}
+
+//GetConfigTransport 指定配置使用的Transport,未单独设置时返回公共的Transport
+func (client *RestClient) GetConfigTransport(name string) *http.Transport {
+	snapshot := client.manager.load()
+	if transport, ok := snapshot.configTransport[name]; ok {
+		return transport
+	}
+	return snapshot.transport
 }
 
 //GetConfig 获取当前使用配置
@@ -117,7 +139,7 @@ func (client *RestClient) GetConfig(ctx context.Context) (RestConfig, error) {
 	if err != nil {
 		return nil, err
 	}
-	config, ok := client.config[configName]
+	config, ok := client.manager.load().restConfig[configName]
 	if !ok {
 		return nil, NewRestClientError("1", "rest config is exits:"+configName)
 	}
@@ -125,27 +147,58 @@ func (client *RestClient) GetConfig(ctx context.Context) (RestConfig, error) {
 }
 
 //Do 执行请求
-func (client *RestClient) Do(ctx context.Context, key int, param interface{}) chan *RestResult {
+//@param key 接口KEY,可为 int 常量、string 或实现 fmt.Stringer 的整数类型,参见 RestKey
+func (client *RestClient) Do(ctx context.Context, key interface{}, param interface{}) chan *RestResult {
 	rc := make(chan *RestResult, 1)
-	reqs, err := client.Api.ConfigBuilds(ctx)
+	restKey, err := newApiRestKey(client.Api, key)
 	if err != nil {
 		rc <- NewRestResultFromError(err, nil)
+		close(rc)
 		return rc
 	}
-	build, find := reqs[key]
-	if !find {
-		rc <- NewRestResultFromError(NewRestClientError("2", "not find rest api"), nil)
+	build, err := client.findBuild(ctx, restKey)
+	if err != nil {
+		rc <- NewRestResultFromError(err, nil)
 		close(rc)
 	} else {
-		caller := callerFileInfo("rest_client/rest_client.go", 1, 15)
+		caller := restCallerFrom(ctx, 0)
 		go func() {
 			defer func() {
 				if info := recover(); info != nil {
-					rc <- NewRestResultFromError(NewRestClientError("3", fmt.Sprintf("panic %v", info)), nil)
+					rc <- NewRestResultFromError(NewRestClientError("3", fmt.Sprintf("panic %v [%s]", info, restKey)), nil)
 					close(rc)
 				}
 			}()
-			res := build.BuildRequest(ctx, client, key, param, caller)
+			ctx, release, res := client.acquireBulkhead(ctx)
+			if res != nil {
+				rc <- res
+				close(rc)
+				return
+			}
+			defer func() {
+				if res == nil {
+					release()
+				}
+			}()
+			if kBuild, ok := build.(RestKeyBuild); ok {
+				res = kBuild.BuildKeyRequest(ctx, client, restKey, param, caller)
+			} else {
+				res = build.BuildRequest(ctx, client, restKey.Int(), param, caller)
+			}
+			//执行位置在返回内容读取完或关闭后释放,慢速返回内容也占用并发数
+			//调用方丢弃未关闭的结果时,由结果被回收时的关闭释放
+			if res.err != nil || res.response == nil || res.response.Body == nil || res.bodyReadOffset >= 0 {
+				release()
+			} else {
+				res.onClose(release)
+			}
+			if res.caller == nil {
+				res.caller = caller
+			}
+			if item := client.manager.getJsonSchema(client.Api, restKey); item != nil && item.autoCheck && res.schema == nil {
+				res.schema = item.schema
+			}
+			client.manager.trackResult(res)
 			rc <- res
 			close(rc)
 		}()
@@ -153,7 +206,22 @@ func (client *RestClient) Do(ctx context.Context, key int, param interface{}) ch
 	return rc
 }
 
+//RestBodyTooLargeError 返回内容超过最大字节数
+type RestBodyTooLargeError struct {
+	Limit int64
+}
+
+func (err *RestBodyTooLargeError) Error() string {
+	return fmt.Sprintf("response body too large, limit:%d", err.Limit)
+}
+
+//RestTruncateEvent 事件可选实现,返回内容超过最大字节数被截断时回调,可用于统计
+type RestTruncateEvent interface {
+	ResponseTruncate(limit int64)
+}
+
 //RestResult 请求接口后返回数据结构
+//读取完成或调用 JsonResult 后自动关闭,提前放弃读取时需调用 Close
 type RestResult struct {
 	event          RestEvent
 	build          RestBuild
@@ -161,8 +229,23 @@ type RestResult struct {
 	body           string
 	bodyReadOffset int
 	err            error
+	reader         io.Reader //解压及转码后的返回内容
+	maxBodySize    int64
+	bodySize       int64
+	caller         *RestCallerInfo
+	closed         bool
+	eof            bool //内容已读取完,读取完自动关闭后再读取返回 io.EOF
+	finished       bool
+	detector       *RestLeakDetector
+	schema         *JsonSchema
+	envelope       *RestEnvelope
+	errorCodes     RestErrorCodes
+	closeHooks     []func()
 }
 
+//restResultDrainLimit 关闭时最多丢弃的未读取内容,超过时不再复用连接
+const restResultDrainLimit = 64 << 10
+
 //NewRestResultFromError 创建一个错误的请求结果
 func NewRestResultFromError(err error, event RestEvent) *RestResult {
 	result := &RestResult{
@@ -173,9 +256,7 @@ func NewRestResultFromError(err error, event RestEvent) *RestResult {
 		err:            err,
 		response:       nil,
 	}
-	if event != nil {
-		event.ResponseFinish(err)
-	}
+	result.finish(err)
 	return result
 }
 
@@ -193,6 +274,13 @@ func NewRestResult(build RestBuild, response *http.Response, event RestEvent) *R
 	if event != nil && response != nil {
 		event.ResponseHeader(response.StatusCode, response.Header)
 	}
+	if response != nil && response.Body != nil {
+		//调用方未读取也未关闭就丢弃结果时,被回收后丢弃剩余内容并关闭,以便复用连接
+		//丢弃内容可能等待网络,不在回收协程中执行
+		runtime.SetFinalizer(result, func(res *RestResult) {
+			go res.Close()
+		})
+	}
 	return result
 }
 
@@ -212,8 +300,8 @@ func NewRestBodyResult(build RestBuild, body string, response *http.Response, ev
 		if response != nil {
 			event.ResponseHeader(response.StatusCode, response.Header)
 		}
-		event.ResponseFinish(nil)
 	}
+	result.finish(nil)
 	return result
 }
 
@@ -252,27 +340,118 @@ func (res *RestResult) Read(p []byte) (int, error) {
 			return sLen, io.EOF
 		}
 	} else {
-		if res.response == nil {
+		if res.response == nil || res.response.Body == nil {
 			return 0, io.EOF
 		}
-		n, err := res.response.Body.Read(p)
-		if n > 0 {
+		if res.closed {
+			if res.eof {
+				return 0, io.EOF
+			}
+			return 0, NewRestClientError("14", "response body is closed")
+		}
+		if res.reader == nil {
+			var decode *RestDecode
+			if dBuild, ok := res.build.(RestDecodeBuild); ok {
+				decode = dBuild.ResponseDecode()
+			}
+			reader, err := restDecodeReader(res.response.Body, res.response.Header.Get("Content-Encoding"), res.response.Header.Get("Content-Type"), decode)
+			if err != nil {
+				res.err = err
+				res.finish(err)
+				_ = res.Close()
+				return 0, err
+			}
+			res.reader = reader
+		}
+		if res.maxBodySize > 0 && int64(len(p)) > res.maxBodySize-res.bodySize+1 {
+			p = p[0 : res.maxBodySize-res.bodySize+1]
+		}
+		n, err := res.reader.Read(p)
+		if res.maxBodySize > 0 && res.bodySize+int64(n) > res.maxBodySize {
+			n = int(res.maxBodySize - res.bodySize)
+			err = &RestBodyTooLargeError{Limit: res.maxBodySize}
+			if tEvent, ok := res.event.(RestTruncateEvent); ok {
+				tEvent.ResponseTruncate(res.maxBodySize)
+			}
+		}
+		res.bodySize += int64(n)
+		if n > 0 && res.event != nil {
 			res.event.ResponseRead(p[0:n])
 		}
 		if err == io.EOF {
-			if res.event != nil {
-				res.event.ResponseFinish(nil)
-			}
-		} else {
+			res.eof = true
+			res.finish(nil)
+			_ = res.Close()
+		} else if err != nil {
 			res.err = err
-			if res.event != nil {
-				res.event.ResponseFinish(err)
-			}
+			res.finish(err)
+			_ = res.Close()
 		}
 		return n, err
 	}
 }
 
+//finish 内容读取完时回调事件,只回调一次
+func (res *RestResult) finish(err error) {
+	if res.finished {
+		return
+	}
+	res.finished = true
+	if res.event != nil {
+		res.event.ResponseFinish(err)
+	}
+}
+
+//Close 关闭返回内容,未读取完时丢弃少量剩余内容以便复用连接,可重复调用
+func (res *RestResult) Close() error {
+	if res.closed {
+		return nil
+	}
+	res.closed = true
+	runtime.SetFinalizer(res, nil)
+	if res.detector != nil {
+		res.detector.untrack(res)
+	}
+	defer res.runCloseHooks()
+	if res.response == nil || res.response.Body == nil {
+		return nil
+	}
+	if res.bodyReadOffset < 0 {
+		_, _ = io.CopyN(ioutil.Discard, res.response.Body, restResultDrainLimit)
+		res.finish(nil)
+	}
+	return res.response.Body.Close()
+}
+
+//onClose 注册关闭时的回调,用于结束请求上下文等,已关闭时立即执行
+func (res *RestResult) onClose(hook func()) {
+	if res.closed {
+		hook()
+		return
+	}
+	res.closeHooks = append(res.closeHooks, hook)
+}
+
+//runCloseHooks 执行关闭回调,只执行一次
+func (res *RestResult) runCloseHooks() {
+	hooks := res.closeHooks
+	res.closeHooks = nil
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
+//WithMaxBodySize 设置返回内容最大字节数,超过时读取返回 RestBodyTooLargeError
+func (res *RestResult) WithMaxBodySize(size int64) *RestResult {
+	res.maxBodySize = size
+	return res
+}
+
+//Caller 发起请求的调用方信息,非 Do 创建的结果为nil
+func (res *RestResult) Caller() *RestCallerInfo {
+	return res.caller
+}
+
 //Err 返回错误,无错误返回nil
 func (res *RestResult) Err() error {
 	return res.err
@@ -285,6 +464,7 @@ func (res *RestResult) JsonResult(path ...string) *JsonResult {
 			res.event.ResponseCheck(res.err)
 		}
 	}()
+	defer res.Close()
 	if res.err != nil {
 		return NewJsonResultFromError(res.err)
 	}
@@ -292,16 +472,65 @@ func (res *RestResult) JsonResult(path ...string) *JsonResult {
 	if err != nil {
 		return NewJsonResultFromError(res.err)
 	}
-	bodyStr := string(body)
-	if check, ok := res.build.(RestJsonResult); ok {
+	//body 不再修改,直接转换避免复制大的返回内容
+	bodyStr := restBytesString(body)
+	var check RestJsonResult
+	if res.envelope != nil {
+		check = res.envelope
+	} else if tCheck, ok := res.build.(RestJsonResult); ok {
+		check = tCheck
+	}
+	if check != nil {
 		res.err = check.CheckJsonResult(bodyStr)
 		if res.err != nil {
+			res.err = res.errorCodes.define(res.err)
 			return NewJsonResultFromError(res.err)
 		}
 	}
 	basePath := ""
 	if path != nil {
 		basePath = path[0]
+	} else if res.envelope != nil {
+		basePath = res.envelope.DataPath
 	}
-	return NewJsonResult(bodyStr, basePath)
+	result := NewJsonResult(bodyStr, basePath)
+	if res.schema != nil {
+		if res.err = result.ValidSchema(res.schema); res.err != nil {
+			return NewJsonResultFromError(res.err)
+		}
+	}
+	return result
+}
+
+//XmlResult 将结果转为XML结果
+func (res *RestResult) XmlResult(path ...string) *XmlResult {
+	defer func() {
+		if res.event != nil {
+			res.event.ResponseCheck(res.err)
+		}
+	}()
+	defer res.Close()
+	if res.err != nil {
+		return NewXmlResultFromError(res.err)
+	}
+	body, err := ioutil.ReadAll(res)
+	if err != nil {
+		return NewXmlResultFromError(res.err)
+	}
+	basePath := ""
+	if path != nil {
+		basePath = path[0]
+	}
+	result := NewXmlResult(string(body), basePath)
+	if result.Err() != nil {
+		res.err = result.Err()
+		return result
+	}
+	if check, ok := res.build.(RestXmlResult); ok {
+		res.err = check.CheckXmlResult(result)
+		if res.err != nil {
+			return NewXmlResultFromError(res.err)
+		}
+	}
+	return result
 }
@@ -3,50 +3,246 @@ package rest_client
 import (
 	"net"
 	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 /////////////// 对外接口部分//////////////////
 
+// restConfigSnapshot 配置快照,存入 RestClientManager 后只读
+type restConfigSnapshot struct {
+	restConfig      map[string]RestConfig
+	transport       *http.Transport
+	configTransport map[string]*http.Transport
+	bulkhead        map[string]*RestBulkhead
+	loaded          map[string]*RestConfigItem //从配置文件加载的原始配置,用于重新加载时对比
+	loadedTransport *RestTransportConfig
+}
+
+func (snapshot *restConfigSnapshot) clone() *restConfigSnapshot {
+	tmp := &restConfigSnapshot{
+		restConfig:      make(map[string]RestConfig, len(snapshot.restConfig)),
+		transport:       snapshot.transport,
+		loadedTransport: snapshot.loadedTransport,
+		configTransport: make(map[string]*http.Transport, len(snapshot.configTransport)),
+		bulkhead:        make(map[string]*RestBulkhead, len(snapshot.bulkhead)),
+		loaded:          make(map[string]*RestConfigItem, len(snapshot.loaded)),
+	}
+	for key, val := range snapshot.restConfig {
+		tmp.restConfig[key] = val
+	}
+	for key, val := range snapshot.configTransport {
+		tmp.configTransport[key] = val
+	}
+	for key, val := range snapshot.bulkhead {
+		tmp.bulkhead[key] = val
+	}
+	for key, val := range snapshot.loaded {
+		tmp.loaded[key] = val
+	}
+	return tmp
+}
+
+// transports 快照使用的全部Transport
+func (snapshot *restConfigSnapshot) transports() map[*http.Transport]bool {
+	tmp := map[*http.Transport]bool{snapshot.transport: true}
+	for _, val := range snapshot.configTransport {
+		tmp[val] = true
+	}
+	return tmp
+}
+
+// RestConfigValid 配置可选实现,通过 UpdateRestConfig 或重新加载配置时校验
+type RestConfigValid interface {
+	Valid() error
+}
+
 type RestClientManager struct {
-	restConfig map[string]RestConfig
-	transport  *http.Transport
+	lock       sync.Mutex
+	snapshot   atomic.Value
+	previous   *restConfigSnapshot
+	listeners  []func(names []string, err error)
+	tableLock  sync.RWMutex
+	tables     map[reflect.Type]*restBuildTable
+	schemas    map[reflect.Type]map[restKeyIndex]*restJsonSchema
+	errorCodes map[string]RestErrorCodes
+	detector   atomic.Value
+	//headerTransports 接口超时大于 Transport 等待HEADER超时时使用的副本,*http.Transport => *http.Transport
+	headerTransports sync.Map
 }
 
 func (c *RestClientManager) NewApi(api RestApi) *RestClient {
 	rest := &RestClient{
-		Api:       api,
-		config:    c.restConfig,
-		transport: c.transport,
+		Api:     api,
+		manager: c,
 	}
 	return rest
 }
 
+// load 当前配置快照
+func (c *RestClientManager) load() *restConfigSnapshot {
+	return c.snapshot.Load().(*restConfigSnapshot)
+}
+
+// update 在当前配置的副本上修改,校验通过后原子替换,失败时保持原配置
+// @param modify 修改配置,返回变更的配置名
+func (c *RestClientManager) update(valid bool, modify func(snapshot *restConfigSnapshot) ([]string, error)) error {
+	c.lock.Lock()
+	current := c.load()
+	next := current.clone()
+	names, err := modify(next)
+	if err == nil && valid {
+		for _, name := range names {
+			if vConfig, ok := next.restConfig[name].(RestConfigValid); ok {
+				if err = vConfig.Valid(); err != nil {
+					break
+				}
+			}
+		}
+	}
+	if err == nil {
+		c.previous = current
+		c.snapshot.Store(next)
+	}
+	c.lock.Unlock()
+	if err == nil {
+		closeUnusedTransport(current, next)
+		c.closeHeaderTransports(next)
+	}
+	c.notify(names, err)
+	return err
+}
+
+// notify 通知配置变更
+func (c *RestClientManager) notify(names []string, err error) {
+	c.lock.Lock()
+	listeners := c.listeners
+	c.lock.Unlock()
+	for _, listener := range listeners {
+		listener(names, err)
+	}
+}
+
+// closeUnusedTransport 关闭新配置中不再使用的Transport的空闲连接
+func closeUnusedTransport(current *restConfigSnapshot, next *restConfigSnapshot) {
+	used := next.transports()
+	for transport := range current.transports() {
+		if !used[transport] {
+			transport.CloseIdleConnections()
+		}
+	}
+}
+
 //SetRestConfig 设置外部接口配置
 func (c *RestClientManager) SetRestConfig(config RestConfig) *RestClientManager {
-	c.restConfig[config.GetName()] = config
+	_ = c.update(false, func(snapshot *restConfigSnapshot) ([]string, error) {
+		snapshot.restConfig[config.GetName()] = config
+		return []string{config.GetName()}, nil
+	})
+	return c
+}
+
+//UpdateRestConfig 校验并更新外部接口配置,任一配置校验失败时全部不更新
+func (c *RestClientManager) UpdateRestConfig(configs ...RestConfig) error {
+	return c.update(true, func(snapshot *restConfigSnapshot) ([]string, error) {
+		names := make([]string, 0, len(configs))
+		for _, config := range configs {
+			snapshot.restConfig[config.GetName()] = config
+			names = append(names, config.GetName())
+		}
+		return names, nil
+	})
+}
+
+//SetTransport 为指定配置设置独立的Transport,未设置的配置使用公共Transport
+func (c *RestClientManager) SetTransport(name string, transport *http.Transport) *RestClientManager {
+	_ = c.update(false, func(snapshot *restConfigSnapshot) ([]string, error) {
+		snapshot.configTransport[name] = transport
+		return []string{name}, nil
+	})
 	return c
 }
 
+//SetBulkhead 为指定配置设置并发隔离
+func (c *RestClientManager) SetBulkhead(name string, bulkhead *RestBulkhead) *RestClientManager {
+	_ = c.update(false, func(snapshot *restConfigSnapshot) ([]string, error) {
+		snapshot.bulkhead[name] = bulkhead
+		return []string{name}, nil
+	})
+	return c
+}
+
+//OnConfigChange 注册配置变更通知,更新失败并保持原配置时err不为nil
+func (c *RestClientManager) OnConfigChange(listener func(names []string, err error)) *RestClientManager {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.listeners = append(c.listeners, listener)
+	return c
+}
+
+//Rollback 回滚到上一次更新前的配置
+func (c *RestClientManager) Rollback() error {
+	return c.update(false, func(snapshot *restConfigSnapshot) ([]string, error) {
+		if c.previous == nil {
+			return nil, NewRestClientError("6", "not find previous rest config")
+		}
+		*snapshot = *c.previous.clone()
+		names := make([]string, 0, len(snapshot.restConfig))
+		for name := range snapshot.restConfig {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return names, nil
+	})
+}
+
+//ConfigNames 当前全部配置名
+func (c *RestClientManager) ConfigNames() []string {
+	snapshot := c.load()
+	names := make([]string, 0, len(snapshot.restConfig))
+	for name := range snapshot.restConfig {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *RestClientManager) getBulkhead(name string) *RestBulkhead {
+	return c.load().bulkhead[name]
+}
+
+//NewRestTransport 创建默认配置的Transport
+func NewRestTransport() *http.Transport {
+	return &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 300 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          120,
+		MaxIdleConnsPerHost:   12,
+		IdleConnTimeout:       15 * time.Second,
+		ResponseHeaderTimeout: 60 * time.Second, //默认到header的等待时间最长
+	}
+}
+
 //NewRestClientManager 新建REST客户端
 func NewRestClientManager(transport ...*http.Transport) *RestClientManager {
 	var setTransport *http.Transport
 	if transport == nil {
-		setTransport = &http.Transport{
-			DialContext: (&net.Dialer{
-				Timeout:   30 * time.Second,
-				KeepAlive: 300 * time.Second,
-			}).DialContext,
-			MaxIdleConns:          120,
-			MaxIdleConnsPerHost:   12,
-			IdleConnTimeout:       15 * time.Second,
-			ResponseHeaderTimeout: 60 * time.Second, //默认到header的等待时间最长
-		}
+		setTransport = NewRestTransport()
 	} else {
 		setTransport = transport[0]
 	}
-	return &RestClientManager{
-		restConfig: make(map[string]RestConfig),
-		transport:  setTransport,
-	}
+	manager := &RestClientManager{}
+	manager.snapshot.Store(&restConfigSnapshot{
+		restConfig:      make(map[string]RestConfig),
+		transport:       setTransport,
+		configTransport: make(map[string]*http.Transport),
+		bulkhead:        make(map[string]*RestBulkhead),
+		loaded:          make(map[string]*RestConfigItem),
+	})
+	return manager
 }
@@ -0,0 +1,260 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RestCodec 请求参数及返回内容编解码
+type RestCodec interface {
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// RestJsonCodec JSON编解码
+type RestJsonCodec struct{}
+
+func (codec *RestJsonCodec) ContentType() string {
+	return "application/json"
+}
+
+func (codec *RestJsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (codec *RestJsonCodec) Unmarshal(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// RestProtobufCodec protobuf编解码,参数及结果需为 proto.Message
+type RestProtobufCodec struct{}
+
+func (codec *RestProtobufCodec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (codec *RestProtobufCodec) Marshal(v interface{}) ([]byte, error) {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return nil, NewRestClientError("16", "protobuf param must be proto.Message")
+	}
+	return proto.Marshal(msg)
+}
+
+func (codec *RestProtobufCodec) Unmarshal(data []byte, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return NewRestClientError("16", "protobuf result must be proto.Message")
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+// RestMsgpackCodec MessagePack编解码
+type RestMsgpackCodec struct{}
+
+func (codec *RestMsgpackCodec) ContentType() string {
+	return "application/x-msgpack"
+}
+
+func (codec *RestMsgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (codec *RestMsgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+var restCodecLock sync.RWMutex
+var restCodecs = map[string]RestCodec{
+	"application/json":       &RestJsonCodec{},
+	"application/x-protobuf": &RestProtobufCodec{},
+	"application/protobuf":   &RestProtobufCodec{},
+	"application/x-msgpack":  &RestMsgpackCodec{},
+	"application/msgpack":    &RestMsgpackCodec{},
+}
+
+// RegisterRestCodec 注册编解码,返回内容按 Content-Type 选择
+// @param contentType 可不传,默认使用 codec.ContentType()
+func RegisterRestCodec(codec RestCodec, contentType ...string) {
+	restCodecLock.Lock()
+	defer restCodecLock.Unlock()
+	if contentType == nil {
+		contentType = []string{codec.ContentType()}
+	}
+	for _, tmp := range contentType {
+		restCodecs[strings.ToLower(tmp)] = codec
+	}
+}
+
+// GetRestCodec 按 Content-Type 获取编解码,未注册时返回nil
+func GetRestCodec(contentType string) RestCodec {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+	restCodecLock.RLock()
+	defer restCodecLock.RUnlock()
+	return restCodecs[strings.ToLower(contentType)]
+}
+
+// RestCodecBuild RestBuild 可选实现,返回内容未设置 Content-Type 时使用的编解码
+type RestCodecBuild interface {
+	ResponseCodec() RestCodec
+}
+
+// RestUrlConfig 配置可选实现,提供服务地址
+type RestUrlConfig interface {
+	RestConfig
+	BaseUrl() string
+}
+
+var restCodecValid = newJsonValidate()
+
+// Unmarshal 按返回 Content-Type 解析结果到 v 并校验,参数同 JsonResult.GetStruct
+func (res *RestResult) Unmarshal(v interface{}, jsonValid ...*JsonValid) error {
+	defer func() {
+		if res.event != nil {
+			res.event.ResponseCheck(res.err)
+		}
+	}()
+	defer res.Close()
+	if res.err != nil {
+		return res.err
+	}
+	body, err := ioutil.ReadAll(res)
+	if err != nil {
+		return res.err
+	}
+	var codec RestCodec
+	if res.response != nil {
+		if contentType := res.response.Header.Get("Content-Type"); len(contentType) > 0 {
+			if codec = GetRestCodec(contentType); codec == nil {
+				res.err = NewRestClientError("16", "response content type not support:"+contentType)
+				return res.err
+			}
+		}
+	}
+	if codec == nil {
+		if cBuild, ok := res.build.(RestCodecBuild); ok {
+			codec = cBuild.ResponseCodec()
+		}
+	}
+	if codec == nil {
+		codec = &RestJsonCodec{}
+	}
+	if res.err = codec.Unmarshal(body, v); res.err != nil {
+		return res.err
+	}
+	res.err = validStruct(restCodecValid, v, jsonValid)
+	return res.err
+}
+
+// CodecRestBuild 使用指定编解码的接口配置,服务配置需实现 RestUrlConfig
+// 服务配置实现 RestBuildsConfig 时使用其中的返回内容最大字节数及接口覆盖配置
+type CodecRestBuild struct {
+	Timeout     time.Duration //指定接口超时时间,默认0,跟全局一致
+	Path        string        //接口路径
+	HttpMethod  string        //默认POST
+	Codec       RestCodec     //请求参数编码,默认JSON
+	Accept      []string      //可接受的返回内容类型,默认为 Codec 的类型
+	Decode      *RestDecode   //返回内容解码配置
+	MaxBodySize int64         //返回内容最大字节数,默认0不限制
+}
+
+// BuildInfo 接口信息
+func (clt *CodecRestBuild) BuildInfo() *RestEndpoint {
+	return &RestEndpoint{
+		HttpMethod: clt.method(),
+		Path:       clt.Path,
+		Timeout:    clt.Timeout,
+	}
+}
+
+// ResponseDecode 返回内容解码配置
+func (clt *CodecRestBuild) ResponseDecode() *RestDecode {
+	return clt.Decode
+}
+
+// ResponseCodec 返回内容编解码
+func (clt *CodecRestBuild) ResponseCodec() RestCodec {
+	return clt.codec()
+}
+
+func (clt *CodecRestBuild) codec() RestCodec {
+	if clt.Codec == nil {
+		return &RestJsonCodec{}
+	}
+	return clt.Codec
+}
+
+func (clt *CodecRestBuild) method() string {
+	if len(clt.HttpMethod) == 0 {
+		return http.MethodPost
+	}
+	return clt.HttpMethod
+}
+
+// BuildRequest 执行请求
+func (clt *CodecRestBuild) BuildRequest(ctx context.Context, client *RestClient, key int, param interface{}, callerInfo *RestCallerInfo) *RestResult {
+	return clt.BuildKeyRequest(ctx, client, restIntKey(key), param, callerInfo)
+}
+
+// BuildKeyRequest 按接口KEY执行请求
+func (clt *CodecRestBuild) BuildKeyRequest(ctx context.Context, client *RestClient, key RestKey, param interface{}, _ *RestCallerInfo) *RestResult {
+	tConfig, err := client.GetConfig(ctx)
+	if err != nil {
+		return NewRestResultFromError(err, &RestEventNoop{})
+	}
+	config, ok := tConfig.(RestUrlConfig)
+	if !ok {
+		return NewRestResultFromError(NewRestClientError("11", "build config is wrong"), &RestEventNoop{})
+	}
+	event := restBuildEvent(ctx, config, key)
+
+	codec := clt.codec()
+	var body []byte
+	if param != nil {
+		if body, err = codec.Marshal(param); err != nil {
+			return NewRestResultFromError(err, event)
+		}
+	}
+	apiUrl := config.BaseUrl() + clt.Path
+	event.RequestStart(clt.method(), apiUrl)
+	send := newRestTimeoutSend(ctx)
+	req, err := http.NewRequestWithContext(send.ctx, clt.method(), apiUrl, NewRestRequestReader(bytes.NewReader(body), event))
+	if err != nil {
+		send.cancel()
+		return NewRestResultFromError(err, event)
+	}
+	req.ContentLength = int64(len(body))
+	if len(body) > 0 {
+		req.Header.Set("Content-Type", codec.ContentType())
+	}
+	accept := clt.Accept
+	if accept == nil {
+		accept = []string{codec.ContentType()}
+	}
+	req.Header.Set("Accept", strings.Join(accept, ", "))
+
+	timeout, maxBodySize := restBuildLimit(config, key, clt.Timeout, clt.MaxBodySize)
+	send.start(timeout)
+	httpClient := &http.Client{
+		Transport: client.headerTransport(client.GetConfigTransport(config.GetName()), timeout),
+	}
+	res, err := send.finish(httpClient.Do(req))
+	if err != nil {
+		return NewRestResultFromError(err, event)
+	}
+	return NewRestResult(clt, res, event).WithMaxBodySize(maxBodySize)
+}
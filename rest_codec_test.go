@@ -0,0 +1,162 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testCodecApi struct{}
+
+const (
+	testCodecProto   = iota
+	testCodecMsgpack = iota
+)
+
+func (res *testCodecApi) ConfigBuilds(_ context.Context) (map[int]RestBuild, error) {
+	return map[int]RestBuild{
+		testCodecProto: &CodecRestBuild{
+			Path:  "/proto",
+			Codec: &RestProtobufCodec{},
+		},
+		testCodecMsgpack: &CodecRestBuild{
+			Path:   "/msgpack",
+			Codec:  &RestMsgpackCodec{},
+			Accept: []string{"application/x-msgpack", "application/json"},
+		},
+	}, nil
+}
+
+func (res *testCodecApi) ConfigName(_ context.Context) (string, error) {
+	return "test111", nil
+}
+
+type testCodecUser struct {
+	Name string `msgpack:"name" json:"name" validate:"required"`
+	Age  int    `msgpack:"age" json:"age" validate:"gte=18"`
+}
+
+func TestCodecRestBuild(t *testing.T) {
+	var accept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		accept = r.Header.Get("Accept")
+		switch r.URL.Path {
+		case "/proto":
+			var req wrapperspb.StringValue
+			if r.Header.Get("Content-Type") != "application/x-protobuf" || proto.Unmarshal(data, &req) != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			out, _ := proto.Marshal(wrapperspb.String("hello " + req.Value))
+			w.Header().Set("Content-Type", "application/x-protobuf")
+			_, _ = w.Write(out)
+		case "/msgpack":
+			var user testCodecUser
+			if msgpack.Unmarshal(data, &user) != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if user.Age > 100 {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"name":"old","age":10}`))
+				return
+			}
+			out, _ := msgpack.Marshal(&user)
+			w.Header().Set("Content-Type", "application/x-msgpack")
+			_, _ = w.Write(out)
+		}
+	}))
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	api := client.NewApi(&testCodecApi{})
+
+	var str wrapperspb.StringValue
+	if err := (<-api.Do(context.Background(), testCodecProto, wrapperspb.String("rest"))).Unmarshal(&str); err != nil {
+		t.Fatal(err)
+	}
+	if str.Value != "hello rest" || accept != "application/x-protobuf" {
+		t.Errorf("protobuf result wrong:%s", str.Value)
+	}
+
+	var user testCodecUser
+	if err := (<-api.Do(context.Background(), testCodecMsgpack, &testCodecUser{Name: "a", Age: 20})).Unmarshal(&user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Name != "a" || user.Age != 20 || accept != "application/x-msgpack, application/json" {
+		t.Error("msgpack result wrong")
+	}
+	if err := (<-api.Do(context.Background(), testCodecMsgpack, &testCodecUser{Name: "a", Age: 10})).Unmarshal(&user); err == nil {
+		t.Error("msgpack result valid must fail")
+	}
+	user = testCodecUser{}
+	err := (<-api.Do(context.Background(), testCodecMsgpack, &testCodecUser{Name: "a", Age: 200})).Unmarshal(&user)
+	if err == nil || user.Name != "old" {
+		t.Error("json content type must decode by json and valid")
+	}
+	if err := (<-api.Do(context.Background(), testCodecProto, "rest")).Err(); err == nil {
+		t.Error("protobuf param must be proto.Message")
+	}
+}
+
+func TestGetRestCodec(t *testing.T) {
+	if _, ok := GetRestCodec("application/json; charset=utf-8").(*RestJsonCodec); !ok {
+		t.Error("json codec wrong")
+	}
+	if _, ok := GetRestCodec("application/msgpack").(*RestMsgpackCodec); !ok {
+		t.Error("msgpack codec wrong")
+	}
+	if GetRestCodec("text/html") != nil {
+		t.Error("not register codec must nil")
+	}
+	RegisterRestCodec(&RestJsonCodec{}, "text/json")
+	if GetRestCodec("text/json") == nil {
+		t.Error("register codec wrong")
+	}
+}
+
+func TestCodecRestBuildConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		out, _ := msgpack.Marshal(&testCodecUser{Name: "long name", Age: 20})
+		w.Header().Set("Content-Type", "application/x-msgpack")
+		_, _ = w.Write(out)
+	}))
+	defer server.Close()
+	event := &testBulkheadEvent{}
+	config := &AppRestConfig{
+		Name:        "test111",
+		AppUrl:      server.URL,
+		MaxBodySize: 4,
+		EventCreate: func(_ context.Context) RestEvent {
+			return event
+		},
+	}
+	client := NewRestClientManager()
+	client.SetRestConfig(config)
+	client.SetBulkhead("test111", NewRestBulkhead(1, 0, 0))
+	api := client.NewApi(&testCodecApi{})
+	var user testCodecUser
+	if err := (<-api.Do(context.Background(), testCodecMsgpack, &testCodecUser{})).Unmarshal(&user); err == nil {
+		t.Error("config max body size must apply")
+	}
+	config.Builds = map[string]*RestBuildConfig{
+		"1": {MaxBodySize: 1024},
+	}
+	if err := (<-api.Do(context.Background(), testCodecMsgpack, &testCodecUser{})).Unmarshal(&user); err != nil {
+		t.Error(err)
+	}
+	event.mu.Lock()
+	defer event.mu.Unlock()
+	if len(event.waits) != 2 {
+		t.Error("bulkhead event must call")
+	}
+}
@@ -0,0 +1,467 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RestConfigError 配置错误,Key 为出错的配置路径,如 configs.product.app_url
+type RestConfigError struct {
+	Key string
+	Msg string
+}
+
+func (err *RestConfigError) Error() string {
+	return err.Key + ": " + err.Msg
+}
+
+// NewRestConfigError 配置错误创建
+func NewRestConfigError(key string, msg string) *RestConfigError {
+	return &RestConfigError{
+		Key: key,
+		Msg: msg,
+	}
+}
+
+// RestDuration 配置文件中的时间,支持 "3s" 格式或纳秒数字
+type RestDuration time.Duration
+
+func (duration *RestDuration) parse(value string) error {
+	if num, err := strconv.ParseInt(value, 10, 64); err == nil {
+		*duration = RestDuration(num)
+		return nil
+	}
+	tmp, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*duration = RestDuration(tmp)
+	return nil
+}
+
+func (duration *RestDuration) UnmarshalJSON(data []byte) error {
+	return duration.parse(strings.Trim(string(data), "\""))
+}
+
+func (duration *RestDuration) UnmarshalYAML(value *yaml.Node) error {
+	return duration.parse(value.Value)
+}
+
+// RestTransportConfig Transport配置,未设置的项使用 NewRestTransport 的默认值
+type RestTransportConfig struct {
+	DialTimeout           RestDuration `json:"dial_timeout" yaml:"dial_timeout" validate:"gte=0"`
+	KeepAlive             RestDuration `json:"keep_alive" yaml:"keep_alive" validate:"gte=0"`
+	MaxIdleConns          int          `json:"max_idle_conns" yaml:"max_idle_conns" validate:"gte=0"`
+	MaxIdleConnsPerHost   int          `json:"max_idle_conns_per_host" yaml:"max_idle_conns_per_host" validate:"gte=0"`
+	MaxConnsPerHost       int          `json:"max_conns_per_host" yaml:"max_conns_per_host" validate:"gte=0"`
+	IdleConnTimeout       RestDuration `json:"idle_conn_timeout" yaml:"idle_conn_timeout" validate:"gte=0"`
+	ResponseHeaderTimeout RestDuration `json:"response_header_timeout" yaml:"response_header_timeout" validate:"gte=0"`
+}
+
+// RestBulkheadConfig 并发隔离配置
+type RestBulkheadConfig struct {
+	MaxConcurrent int          `json:"max_concurrent" yaml:"max_concurrent" validate:"gt=0"`
+	MaxQueue      int          `json:"max_queue" yaml:"max_queue" validate:"gte=0"`
+	QueueTimeout  RestDuration `json:"queue_timeout" yaml:"queue_timeout" validate:"gte=0"`
+}
+
+// RestBuildConfig 单个接口的覆盖配置,未设置的项使用 RestBuild 中的配置
+type RestBuildConfig struct {
+	Timeout     RestDuration `json:"timeout" yaml:"timeout" validate:"gte=0"`
+	MaxBodySize int64        `json:"max_body_size" yaml:"max_body_size" validate:"gte=0"`
+}
+
+// RestConfigItem 单个服务配置
+type RestConfigItem struct {
+	AppKey      string                      `json:"app_key" yaml:"app_key" validate:"required"`
+	AppSecret   string                      `json:"app_secret" yaml:"app_secret"`
+	AppUrl      string                      `json:"app_url" yaml:"app_url" validate:"required,url"`
+	MaxBodySize int64                       `json:"max_body_size" yaml:"max_body_size" validate:"gte=0"`
+	Transport   *RestTransportConfig        `json:"transport" yaml:"transport"`
+	Bulkhead    *RestBulkheadConfig         `json:"bulkhead" yaml:"bulkhead"`
+	Envelope    *RestEnvelope               `json:"envelope" yaml:"envelope"`
+	Builds      map[string]*RestBuildConfig `json:"builds" yaml:"builds" validate:"dive,required"`
+}
+
+// RestConfigFile 配置文件结构
+type RestConfigFile struct {
+	Transport *RestTransportConfig       `json:"transport" yaml:"transport"`
+	Configs   map[string]*RestConfigItem `json:"configs" yaml:"configs" validate:"required,dive,required"`
+}
+
+var restConfigEnvReg = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?}`)
+
+// restConfigEnv 替换配置值中的 ${ENV} 及 ${ENV:-默认值}
+func restConfigEnv(key string, value string) (string, error) {
+	var err error
+	value = restConfigEnvReg.ReplaceAllStringFunc(value, func(match string) string {
+		sub := restConfigEnvReg.FindStringSubmatch(match)
+		if env, ok := os.LookupEnv(sub[1]); ok {
+			return env
+		}
+		if len(sub[2]) > 0 {
+			return sub[3]
+		}
+		if err == nil {
+			err = NewRestConfigError(key, "env "+sub[1]+" is not set")
+		}
+		return ""
+	})
+	return value, err
+}
+
+// restConfigNodeEnv 递归替换配置节点中的环境变量
+func restConfigNodeEnv(key string, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for i, item := range node.Content {
+			itemKey := key
+			if node.Kind == yaml.SequenceNode {
+				itemKey = fmt.Sprintf("%s[%d]", key, i)
+			}
+			if err := restConfigNodeEnv(itemKey, item); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := restConfigNodeEnv(pathCreate(key, node.Content[i].Value), node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		value, err := restConfigEnv(key, node.Value)
+		if err != nil {
+			return err
+		}
+		if value != node.Value {
+			//替换后按新值重新识别类型,使 ${ENV} 可用于数字、布尔等配置
+			node.Value = value
+			node.Tag = ""
+			node.Style &^= yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle
+		}
+	}
+	return nil
+}
+
+var restConfigLineReg = regexp.MustCompile(`line (\d+)`)
+
+// restConfigJsonNode 使用 encoding/json 解析JSON配置为 yaml 节点,与 yaml 配置统一替换环境变量及解码
+// 值节点的行号为解析顺序,用于解码失败时查找配置路径,解析失败时错误中的 Key 为出错位置的配置路径
+func restConfigJsonNode(dec *json.Decoder, key string, line *int) (*yaml.Node, error) {
+	fail := func(err error) (*yaml.Node, error) {
+		errKey := key
+		if len(errKey) == 0 {
+			errKey = fmt.Sprintf("offset %d", dec.InputOffset())
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, NewRestConfigError(errKey, "config parse fail:"+err.Error())
+	}
+	token, err := dec.Token()
+	if err != nil {
+		return fail(err)
+	}
+	*line++
+	node := &yaml.Node{Kind: yaml.ScalarNode, Line: *line}
+	switch tToken := token.(type) {
+	case json.Delim:
+		node.Kind, node.Tag = yaml.SequenceNode, "!!seq"
+		if tToken == '{' {
+			node.Kind, node.Tag = yaml.MappingNode, "!!map"
+		}
+		for i := 0; dec.More(); i++ {
+			itemKey := fmt.Sprintf("%s[%d]", key, i)
+			if node.Kind == yaml.MappingNode {
+				name, err := dec.Token()
+				if err != nil {
+					return fail(err)
+				}
+				itemKey = pathCreate(key, name.(string))
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name.(string)})
+			}
+			item, err := restConfigJsonNode(dec, itemKey, line)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, item)
+		}
+		if _, err = dec.Token(); err != nil {
+			return fail(err)
+		}
+		return node, nil
+	case string:
+		node.Tag, node.Style, node.Value = "!!str", yaml.DoubleQuotedStyle, tToken
+	case json.Number:
+		node.Value = tToken.String()
+	case bool:
+		node.Tag, node.Value = "!!bool", strconv.FormatBool(tToken)
+	default:
+		node.Tag, node.Value = "!!null", "null"
+	}
+	return node, nil
+}
+
+// restConfigNodeKey 查找行号对应的配置路径,未找到时返回空
+func restConfigNodeKey(key string, node *yaml.Node, line int) string {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for i, item := range node.Content {
+			itemKey := key
+			if node.Kind == yaml.SequenceNode {
+				itemKey = fmt.Sprintf("%s[%d]", key, i)
+			}
+			if find := restConfigNodeKey(itemKey, item, line); len(find) > 0 {
+				return find
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			itemKey := pathCreate(key, node.Content[i].Value)
+			if node.Content[i].Line == line && node.Content[i+1].Kind != yaml.ScalarNode {
+				return itemKey
+			}
+			if find := restConfigNodeKey(itemKey, node.Content[i+1], line); len(find) > 0 {
+				return find
+			}
+		}
+	case yaml.ScalarNode:
+		if node.Line == line {
+			return key
+		}
+	}
+	return ""
+}
+
+// ParseRestConfig 解析配置内容,值中可使用 ${ENV} 引用环境变量
+// @param format 内容格式 yaml 或 json
+func ParseRestConfig(data []byte, format string) (*RestConfigFile, error) {
+	var node *yaml.Node
+	switch strings.ToLower(format) {
+	case "json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var err error
+		line := 0
+		if node, err = restConfigJsonNode(dec, "", &line); err != nil {
+			return nil, err
+		}
+		if _, err = dec.Token(); err != io.EOF {
+			return nil, NewRestConfigError(fmt.Sprintf("offset %d", dec.InputOffset()), "config parse fail:invalid data after json value")
+		}
+	case "yaml", "yml":
+		node = &yaml.Node{}
+		if err := yaml.Unmarshal(data, node); err != nil {
+			key := strings.ToLower(format)
+			if line := restConfigLineReg.FindStringSubmatch(err.Error()); line != nil {
+				key = "line " + line[1]
+			}
+			return nil, NewRestConfigError(key, "config parse fail:"+err.Error())
+		}
+	default:
+		return nil, NewRestConfigError("", "config format not support:"+format)
+	}
+	if err := restConfigNodeEnv("", node); err != nil {
+		return nil, err
+	}
+	file := &RestConfigFile{}
+	if err := node.Decode(file); err != nil {
+		key := strings.ToLower(format)
+		if line := restConfigLineReg.FindStringSubmatch(err.Error()); line != nil {
+			num, _ := strconv.Atoi(line[1])
+			if find := restConfigNodeKey("", node, num); len(find) > 0 {
+				key = find
+			}
+		}
+		return nil, NewRestConfigError(key, "config decode fail:"+err.Error())
+	}
+	if err := file.Valid(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// ParseRestConfigFile 解析配置文件,按扩展名区分 yaml 或 json
+func ParseRestConfigFile(path string) (*RestConfigFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRestConfig(data, strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// LoadEnv 从环境变量覆盖配置,变量名为 前缀_配置名_APP_KEY、APP_SECRET、APP_URL
+func (file *RestConfigFile) LoadEnv(prefix string) error {
+	for name, item := range file.Configs {
+		envName := strings.ToUpper(prefix + "_" + name + "_")
+		if env, ok := os.LookupEnv(envName + "APP_KEY"); ok {
+			item.AppKey = env
+		}
+		if env, ok := os.LookupEnv(envName + "APP_SECRET"); ok {
+			item.AppSecret = env
+		}
+		if env, ok := os.LookupEnv(envName + "APP_URL"); ok {
+			item.AppUrl = env
+		}
+	}
+	return file.Valid()
+}
+
+// Valid 校验配置,错误信息中包含出错的配置路径
+func (file *RestConfigFile) Valid() error {
+	valid := validator.New()
+	valid.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return strings.Split(field.Tag.Get("yaml"), ",")[0]
+	})
+	err := valid.Struct(file)
+	if err == nil {
+		return nil
+	}
+	vErrs, ok := err.(validator.ValidationErrors)
+	if !ok || len(vErrs) == 0 {
+		return err
+	}
+	return NewRestConfigError(restConfigKey(vErrs[0].Namespace()), fmt.Sprintf("validate fail on %s %s", vErrs[0].Tag(), vErrs[0].Param()))
+}
+
+// restConfigKey 校验错误的字段路径转为配置路径
+func restConfigKey(namespace string) string {
+	if index := strings.Index(namespace, "."); index >= 0 {
+		namespace = namespace[index+1:]
+	}
+	namespace = strings.ReplaceAll(namespace, "[", ".")
+	return strings.ReplaceAll(namespace, "]", "")
+}
+
+// NewTransport 按配置创建Transport
+func (config *RestTransportConfig) NewTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 300 * time.Second,
+	}
+	if config.DialTimeout > 0 {
+		dialer.Timeout = time.Duration(config.DialTimeout)
+	}
+	if config.KeepAlive > 0 {
+		dialer.KeepAlive = time.Duration(config.KeepAlive)
+	}
+	transport := NewRestTransport()
+	transport.DialContext = dialer.DialContext
+	if config.MaxIdleConns > 0 {
+		transport.MaxIdleConns = config.MaxIdleConns
+	}
+	if config.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	}
+	if config.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = config.MaxConnsPerHost
+	}
+	if config.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = time.Duration(config.IdleConnTimeout)
+	}
+	if config.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = time.Duration(config.ResponseHeaderTimeout)
+	}
+	return transport
+}
+
+// AppRestConfig 转为服务配置
+// @param eventCreate 可以为nil
+func (item *RestConfigItem) AppRestConfig(name string, eventCreate func(ctx context.Context) RestEvent) *AppRestConfig {
+	return &AppRestConfig{
+		Name:        name,
+		AppKey:      item.AppKey,
+		AppSecret:   item.AppSecret,
+		AppUrl:      item.AppUrl,
+		MaxBodySize: item.MaxBodySize,
+		Builds:      item.Builds,
+		EventCreate: eventCreate,
+		Envelope:    item.Envelope,
+	}
+}
+
+// LoadConfig 加载已解析的配置,配置校验失败时保持原配置
+// 之前从配置文件加载但本次已移除的配置会被删除,变更通知中包含新增、修改及删除的配置名
+// @param eventCreate 可不传,传入时作为所有加载配置的 EventCreate
+func (c *RestClientManager) LoadConfig(file *RestConfigFile, eventCreate ...func(ctx context.Context) RestEvent) error {
+	var setEventCreate func(ctx context.Context) RestEvent
+	if eventCreate != nil {
+		setEventCreate = eventCreate[0]
+	}
+	return c.update(true, func(snapshot *restConfigSnapshot) ([]string, error) {
+		if err := file.Valid(); err != nil {
+			return nil, err
+		}
+		if file.Transport != nil && !reflect.DeepEqual(snapshot.loadedTransport, file.Transport) {
+			snapshot.transport = file.Transport.NewTransport()
+			snapshot.loadedTransport = file.Transport
+		} else if file.Transport == nil && snapshot.loadedTransport != nil {
+			snapshot.transport = NewRestTransport()
+			snapshot.loadedTransport = nil
+		}
+		names := make([]string, 0, len(file.Configs))
+		for name := range snapshot.loaded {
+			if _, ok := file.Configs[name]; ok {
+				continue
+			}
+			//配置文件中已移除的配置
+			names = append(names, name)
+			delete(snapshot.restConfig, name)
+			delete(snapshot.configTransport, name)
+			delete(snapshot.bulkhead, name)
+			delete(snapshot.loaded, name)
+		}
+		for name, item := range file.Configs {
+			loaded := snapshot.loaded[name]
+			if loaded != nil && reflect.DeepEqual(loaded, item) {
+				continue
+			}
+			names = append(names, name)
+			snapshot.restConfig[name] = item.AppRestConfig(name, setEventCreate)
+			snapshot.loaded[name] = item
+			if loaded == nil || !reflect.DeepEqual(loaded.Transport, item.Transport) {
+				if item.Transport != nil {
+					snapshot.configTransport[name] = item.Transport.NewTransport()
+				} else {
+					delete(snapshot.configTransport, name)
+				}
+			}
+			if loaded == nil || !reflect.DeepEqual(loaded.Bulkhead, item.Bulkhead) {
+				if item.Bulkhead != nil {
+					snapshot.bulkhead[name] = NewRestBulkhead(item.Bulkhead.MaxConcurrent, item.Bulkhead.MaxQueue, time.Duration(item.Bulkhead.QueueTimeout))
+				} else {
+					delete(snapshot.bulkhead, name)
+				}
+			}
+		}
+		sort.Strings(names)
+		return names, nil
+	})
+}
+
+// LoadConfigFile 从 yaml 或 json 文件加载配置
+// @param eventCreate 可不传,传入时作为所有加载配置的 EventCreate
+func (c *RestClientManager) LoadConfigFile(path string, eventCreate ...func(ctx context.Context) RestEvent) error {
+	file, err := ParseRestConfigFile(path)
+	if err != nil {
+		return err
+	}
+	return c.LoadConfig(file, eventCreate...)
+}
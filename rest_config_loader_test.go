@@ -0,0 +1,225 @@
+package rest_client
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRestConfig(t *testing.T) {
+	_ = os.Setenv("TEST_REST_SECRET", "secret111")
+	defer os.Unsetenv("TEST_REST_SECRET")
+	file, err := ParseRestConfig([]byte(`
+transport:
+  max_idle_conns: 10
+  response_header_timeout: 5s
+configs:
+  test111:
+    app_key: dome1
+    app_secret: ${TEST_REST_SECRET}
+    app_url: ${TEST_REST_URL:-http://127.0.0.1:8080}
+    bulkhead:
+      max_concurrent: 10
+      queue_timeout: 1s
+    envelope:
+      success:
+        code: ["0"]
+      code_path: code
+      data_path: data
+    builds:
+      "0":
+        timeout: 3s
+`), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := file.Configs["test111"]
+	if item.AppSecret != "secret111" || item.AppUrl != "http://127.0.0.1:8080" {
+		t.Error("config env parse wrong")
+	}
+	if time.Duration(item.Builds["0"].Timeout) != 3*time.Second {
+		t.Error("config duration parse wrong")
+	}
+	if time.Duration(file.Transport.ResponseHeaderTimeout) != 5*time.Second {
+		t.Error("config transport parse wrong")
+	}
+	client := NewRestClientManager()
+	if err := client.LoadConfig(file); err != nil {
+		t.Fatal(err)
+	}
+	api := client.NewApi(&testDome1{})
+	config, err := api.GetConfig(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if appConfig, ok := config.(*AppRestConfig); !ok || appConfig.AppKey != "dome1" {
+		t.Error("config load wrong")
+	} else if appConfig.Envelope == nil || appConfig.Envelope.Success["code"][0] != "0" || appConfig.Envelope.DataPath != "data" {
+		t.Error("config envelope wrong")
+	}
+	key, _ := NewRestKey(test1)
+	if timeout, _ := restBuildLimit(config, key, time.Second, 0); timeout != 3*time.Second {
+		t.Error("build timeout override wrong")
+	}
+	if api.GetTransport().MaxIdleConns != 10 {
+		t.Error("transport config wrong")
+	}
+	if client.getBulkhead("test111") == nil {
+		t.Error("bulkhead config wrong")
+	}
+}
+
+func TestParseRestConfigError(t *testing.T) {
+	_, err := ParseRestConfig([]byte(`{"configs":{"test111":{"app_key":"dome1","app_url":"not url"}}}`), "json")
+	if cErr, ok := err.(*RestConfigError); !ok || cErr.Key != "configs.test111.app_url" {
+		t.Error("config error key wrong", err)
+	}
+	_, err = ParseRestConfig([]byte(`{"configs":{"test111":{"app_key":"${TEST_REST_NOT_SET}"}}}`), "json")
+	if cErr, ok := err.(*RestConfigError); !ok || cErr.Key != "configs.test111.app_key" {
+		t.Error("config env error key wrong", err)
+	}
+	file, err := ParseRestConfig([]byte(`{"configs":{"test111":{"app_key":"dome1","app_url":"http:\/\/127.0.0.1\/api"}}}`), "json")
+	if err != nil {
+		t.Fatal(err)
+	} else if file.Configs["test111"].AppUrl != "http://127.0.0.1/api" {
+		t.Error("json escape parse wrong")
+	}
+	_, err = ParseRestConfig([]byte(`{"configs":{"test111":{"app_key":"dome1",}}}`), "json")
+	if cErr, ok := err.(*RestConfigError); !ok || cErr.Key != "configs.test111" {
+		t.Error("json parse error key wrong", err)
+	}
+	_, err = ParseRestConfig([]byte(`{"configs":{"test111":{"app_key":"dome1","app_url":"http://127.0.0.1","max_body_size":"big"}}}`), "json")
+	if cErr, ok := err.(*RestConfigError); !ok || cErr.Key != "configs.test111.max_body_size" {
+		t.Error("json decode error key wrong", err)
+	}
+	_, err = ParseRestConfig([]byte("configs:\n  test111:\n    app_key: dome1\n    max_body_size: big\n"), "yaml")
+	if cErr, ok := err.(*RestConfigError); !ok || cErr.Key != "configs.test111.max_body_size" {
+		t.Error("yaml decode error key wrong", err)
+	}
+	_, err = ParseRestConfig([]byte("configs:\n  test111: [\n"), "yaml")
+	if cErr, ok := err.(*RestConfigError); !ok || !strings.HasPrefix(cErr.Key, "line ") {
+		t.Error("yaml parse error key wrong", err)
+	}
+	_, err = ParseRestConfig([]byte(`configs:`), "toml")
+	if err == nil {
+		t.Error("config format must not support")
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rest_config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "rest.json")
+	if err := ioutil.WriteFile(path, []byte(`{"configs":{"test111":{"app_key":"dome1","app_url":"http://127.0.0.1"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	client := NewRestClientManager()
+	if err := client.LoadConfigFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.NewApi(&testDome1{}).GetConfig(context.Background()); err != nil {
+		t.Error(err)
+	}
+	file, _ := ParseRestConfigFile(path)
+	_ = os.Setenv("TEST_TEST111_APP_URL", "wrong")
+	defer os.Unsetenv("TEST_TEST111_APP_URL")
+	if err := file.LoadEnv("test"); err == nil {
+		t.Error("env config must valid")
+	}
+}
+
+func TestParseRestConfigEnvNumber(t *testing.T) {
+	_ = os.Setenv("TEST_REST_MAX", "1024")
+	_ = os.Setenv("TEST_REST_SECRET", "0123")
+	defer os.Unsetenv("TEST_REST_MAX")
+	defer os.Unsetenv("TEST_REST_SECRET")
+	for format, data := range map[string]string{
+		"yaml": `
+configs:
+  test111:
+    app_key: dome1
+    app_secret: "${TEST_REST_SECRET}"
+    app_url: http://127.0.0.1
+    max_body_size: ${TEST_REST_MAX}
+    bulkhead:
+      max_concurrent: ${TEST_REST_CONCURRENT:-5}
+`,
+		"json": `{"configs":{"test111":{"app_key":"dome1","app_secret":"${TEST_REST_SECRET}","app_url":"http://127.0.0.1","max_body_size":"${TEST_REST_MAX}","bulkhead":{"max_concurrent":"${TEST_REST_CONCURRENT:-5}"}}}}`,
+	} {
+		file, err := ParseRestConfig([]byte(data), format)
+		if err != nil {
+			t.Fatal(format, err)
+		}
+		item := file.Configs["test111"]
+		if item.MaxBodySize != 1024 || item.Bulkhead.MaxConcurrent != 5 {
+			t.Error(format, "config env number parse wrong")
+		}
+		if item.AppSecret != "0123" {
+			t.Error(format, "config env string parse wrong")
+		}
+	}
+}
+
+func TestLoadConfigRemove(t *testing.T) {
+	file, err := ParseRestConfig([]byte(`
+transport:
+  max_idle_conns: 10
+configs:
+  test111:
+    app_key: dome1
+    app_url: http://127.0.0.1
+    transport:
+      max_idle_conns: 5
+    bulkhead:
+      max_concurrent: 10
+  test222:
+    app_key: dome2
+    app_url: http://127.0.0.1
+`), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewRestClientManager()
+	if err := client.LoadConfig(file); err != nil {
+		t.Fatal(err)
+	}
+	var changed []string
+	client.OnConfigChange(func(names []string, err error) {
+		changed = names
+	})
+	file, err = ParseRestConfig([]byte(`
+configs:
+  test222:
+    app_key: dome2
+    app_url: http://127.0.0.1
+`), "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.LoadConfig(file); err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || changed[0] != "test111" {
+		t.Error("removed config not notify")
+	}
+	api := client.NewApi(&testDome1{})
+	if _, err := api.GetConfig(context.Background()); err == nil {
+		t.Error("removed config must delete")
+	}
+	if client.getBulkhead("test111") != nil {
+		t.Error("removed bulkhead must delete")
+	}
+	if api.GetConfigTransport("test111") != api.GetTransport() {
+		t.Error("removed transport must delete")
+	}
+	if api.GetTransport().MaxIdleConns == 10 {
+		t.Error("removed global transport must reset")
+	}
+}
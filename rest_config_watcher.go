@@ -0,0 +1,165 @@
+package rest_client
+
+import (
+	"context"
+	"crypto/sha256"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RestConfigSource 配置来源,用于重新加载配置
+type RestConfigSource interface {
+	Load(ctx context.Context) (data []byte, format string, err error)
+}
+
+// RestFileConfigSource 文件配置来源
+type RestFileConfigSource struct {
+	Path string
+}
+
+// NewRestFileConfigSource 创建文件配置来源,按扩展名区分 yaml 或 json
+func NewRestFileConfigSource(path string) *RestFileConfigSource {
+	return &RestFileConfigSource{Path: path}
+}
+
+func (source *RestFileConfigSource) Load(_ context.Context) ([]byte, string, error) {
+	data, err := ioutil.ReadFile(source.Path)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, strings.TrimPrefix(filepath.Ext(source.Path), "."), nil
+}
+
+// RestHttpConfigSource 远程配置来源,通过GET请求获取配置内容
+type RestHttpConfigSource struct {
+	Url    string
+	Format string //内容格式 yaml 或 json
+	Client *http.Client
+}
+
+// NewRestHttpConfigSource 创建远程配置来源
+func NewRestHttpConfigSource(url string, format string) *RestHttpConfigSource {
+	return &RestHttpConfigSource{
+		Url:    url,
+		Format: format,
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (source *RestHttpConfigSource) Load(ctx context.Context) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.Url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	res, err := source.Client.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, "", NewRestClientError("7", "config source return status:"+res.Status)
+	}
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, source.Format, nil
+}
+
+// RestConfigWatcher 定时检查配置来源,内容变化时重新加载配置
+// 新配置解析或校验失败时保持原配置,错误通过 OnConfigChange 通知
+type RestConfigWatcher struct {
+	manager     *RestClientManager
+	source      RestConfigSource
+	interval    time.Duration
+	eventCreate func(ctx context.Context) RestEvent
+	lock        sync.Mutex
+	hash        [sha256.Size]byte
+	cancel      context.CancelFunc
+}
+
+// NewRestConfigWatcher 创建配置监听
+// @param interval 检查间隔,小于等于0时为30秒
+// @param eventCreate 可不传,传入时作为所有加载配置的 EventCreate
+func (c *RestClientManager) NewRestConfigWatcher(source RestConfigSource, interval time.Duration, eventCreate ...func(ctx context.Context) RestEvent) *RestConfigWatcher {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	watcher := &RestConfigWatcher{
+		manager:  c,
+		source:   source,
+		interval: interval,
+	}
+	if eventCreate != nil {
+		watcher.eventCreate = eventCreate[0]
+	}
+	return watcher
+}
+
+// Reload 检查并加载配置,内容未变化时不做处理
+func (watcher *RestConfigWatcher) Reload(ctx context.Context) error {
+	data, format, err := watcher.source.Load(ctx)
+	if err != nil {
+		watcher.manager.notify(nil, err)
+		return err
+	}
+	hash := sha256.Sum256(data)
+	watcher.lock.Lock()
+	defer watcher.lock.Unlock()
+	if hash == watcher.hash {
+		return nil
+	}
+	file, err := ParseRestConfig(data, format)
+	if err != nil {
+		watcher.manager.notify(nil, err)
+		return err
+	}
+	if watcher.eventCreate != nil {
+		err = watcher.manager.LoadConfig(file, watcher.eventCreate)
+	} else {
+		err = watcher.manager.LoadConfig(file)
+	}
+	if err != nil {
+		return err
+	}
+	watcher.hash = hash
+	return nil
+}
+
+// Start 加载配置并开始定时检查
+func (watcher *RestConfigWatcher) Start(ctx context.Context) error {
+	if err := watcher.Reload(ctx); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	watcher.lock.Lock()
+	watcher.cancel = cancel
+	watcher.lock.Unlock()
+	go func() {
+		ticker := time.NewTicker(watcher.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				_ = watcher.Reload(ctx)
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop 停止定时检查
+func (watcher *RestConfigWatcher) Stop() {
+	watcher.lock.Lock()
+	defer watcher.lock.Unlock()
+	if watcher.cancel != nil {
+		watcher.cancel()
+		watcher.cancel = nil
+	}
+}
@@ -0,0 +1,87 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestRestConfigWatcher(t *testing.T) {
+	var lock sync.Mutex
+	body := `{"configs":{"test111":{"app_key":"dome1","app_secret":"s1","app_url":"http://127.0.0.1"}}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	setBody := func(data string) {
+		lock.Lock()
+		defer lock.Unlock()
+		body = data
+	}
+	client := NewRestClientManager()
+	var changes [][]string
+	var errs []error
+	client.OnConfigChange(func(names []string, err error) {
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			changes = append(changes, names)
+		}
+	})
+	secret := func() string {
+		config, err := client.NewApi(&testDome1{}).GetConfig(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return config.(*AppRestConfig).AppSecret
+	}
+	watcher := client.NewRestConfigWatcher(NewRestHttpConfigSource(server.URL, "json"), 0)
+	if err := watcher.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if secret() != "s1" {
+		t.Error("config load wrong")
+	}
+	if err := watcher.Reload(context.Background()); err != nil || len(changes) != 1 {
+		t.Error("same config must not reload")
+	}
+	setBody(`{"configs":{"test111":{"app_key":"dome1","app_secret":"s2","app_url":"http://127.0.0.1"}}}`)
+	if err := watcher.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if secret() != "s2" || len(changes) != 2 || changes[1][0] != "test111" {
+		t.Error("config reload wrong")
+	}
+	setBody(`{"configs":{"test111":{"app_key":"","app_secret":"s3","app_url":"http://127.0.0.1"}}}`)
+	if err := watcher.Reload(context.Background()); err == nil {
+		t.Error("wrong config must fail")
+	}
+	if secret() != "s2" || len(errs) != 1 {
+		t.Error("wrong config must keep old config")
+	}
+	if err := client.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if secret() != "s1" {
+		t.Error("config rollback wrong")
+	}
+}
+
+func TestUpdateRestConfig(t *testing.T) {
+	client := NewRestClientManager()
+	err := client.UpdateRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppKey: "dome1",
+		AppUrl: "not url",
+	})
+	if err == nil {
+		t.Error("config must valid")
+	}
+	if len(client.ConfigNames()) != 0 {
+		t.Error("wrong config must not set")
+	}
+}
@@ -0,0 +1,82 @@
+package rest_client
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"github.com/andybalholm/brotli"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+	"io"
+	"mime"
+	"strings"
+)
+
+// RestDecode 返回内容解码配置,默认按 Content-Encoding 解压并按 Content-Type 的 charset 转为UTF-8
+type RestDecode struct {
+	DisableDecompress bool   //不解压返回内容
+	DisableCharset    bool   //不转换字符集
+	Charset           string //指定返回内容字符集,如 gbk、gb18030,为空时使用 Content-Type 中的 charset
+}
+
+// RestDecodeBuild RestBuild 可选实现,用于设置接口的返回内容解码
+type RestDecodeBuild interface {
+	ResponseDecode() *RestDecode
+}
+
+// restDecodeReader 按配置包装返回内容
+func restDecodeReader(body io.Reader, encoding string, contentType string, decode *RestDecode) (io.Reader, error) {
+	if decode == nil {
+		decode = &RestDecode{}
+	}
+	reader := body
+	if !decode.DisableDecompress && len(encoding) > 0 {
+		encodings := strings.Split(encoding, ",")
+		for i := len(encodings) - 1; i >= 0; i-- {
+			var err error
+			if reader, err = restDecompress(reader, strings.ToLower(strings.TrimSpace(encodings[i]))); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if decode.DisableCharset {
+		return reader, nil
+	}
+	charset := decode.Charset
+	if len(charset) == 0 && len(contentType) > 0 {
+		if _, params, err := mime.ParseMediaType(contentType); err == nil {
+			charset = params["charset"]
+		}
+	}
+	charset = strings.ToLower(strings.TrimSpace(charset))
+	if len(charset) == 0 || charset == "utf-8" || charset == "utf8" {
+		return reader, nil
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, NewRestClientError("15", "response charset not support:"+charset)
+	}
+	return transform.NewReader(reader, enc.NewDecoder()), nil
+}
+
+// restDecompress 按单个 Content-Encoding 解压
+func restDecompress(reader io.Reader, encoding string) (io.Reader, error) {
+	switch encoding {
+	case "", "identity":
+		return reader, nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(reader)
+	case "deflate":
+		//部分服务返回不带zlib头的deflate内容
+		buf := bufio.NewReader(reader)
+		head, err := buf.Peek(2)
+		if err == nil && head[0]&0x0f == 8 && (uint16(head[0])<<8|uint16(head[1]))%31 == 0 {
+			return zlib.NewReader(buf)
+		}
+		return flate.NewReader(buf), nil
+	case "br":
+		return brotli.NewReader(reader), nil
+	}
+	return nil, NewRestClientError("15", "response encoding not support:"+encoding)
+}
@@ -0,0 +1,104 @@
+package rest_client
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"github.com/andybalholm/brotli"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRestDecodeReader(t *testing.T) {
+	body := `{"result":{"code":"200","state":"ok","message":"成功"}}`
+	compress := func(encoding string) []byte {
+		var buf bytes.Buffer
+		var writer io.WriteCloser
+		switch encoding {
+		case "gzip":
+			writer = gzip.NewWriter(&buf)
+		case "deflate":
+			writer = zlib.NewWriter(&buf)
+		case "raw":
+			writer, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+		case "br":
+			writer = brotli.NewWriter(&buf)
+		}
+		_, _ = writer.Write([]byte(body))
+		_ = writer.Close()
+		return buf.Bytes()
+	}
+	for encoding, data := range map[string][]byte{
+		"gzip":    compress("gzip"),
+		"deflate": compress("deflate"),
+		"Deflate": compress("raw"),
+		"br":      compress("br"),
+	} {
+		reader, err := restDecodeReader(bytes.NewReader(data), encoding, "application/json", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out, _ := ioutil.ReadAll(reader); string(out) != body {
+			t.Errorf("%s decode wrong:%s", encoding, out)
+		}
+	}
+	gbk, _ := simplifiedchinese.GBK.NewEncoder().String(body)
+	reader, _ := restDecodeReader(bytes.NewReader([]byte(gbk)), "", "application/json; charset=GBK", nil)
+	if out, _ := ioutil.ReadAll(reader); string(out) != body {
+		t.Errorf("gbk decode wrong:%s", out)
+	}
+	reader, _ = restDecodeReader(bytes.NewReader([]byte(gbk)), "", "application/json", &RestDecode{Charset: "gb18030"})
+	if out, _ := ioutil.ReadAll(reader); string(out) != body {
+		t.Errorf("gb18030 decode wrong:%s", out)
+	}
+	reader, _ = restDecodeReader(bytes.NewReader([]byte(gbk)), "", "application/json; charset=GBK", &RestDecode{DisableCharset: true})
+	if out, _ := ioutil.ReadAll(reader); string(out) != gbk {
+		t.Error("disable charset wrong")
+	}
+	if _, err := restDecodeReader(bytes.NewReader(nil), "compress", "", nil); err == nil {
+		t.Error("encoding not support")
+	}
+	if _, err := restDecodeReader(bytes.NewReader(nil), "", "text/plain; charset=xxx", nil); err == nil {
+		t.Error("charset not support")
+	}
+}
+
+func TestRestResultDecode(t *testing.T) {
+	body := `{"result":{"code":"200","state":"ok","message":"成功"}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		gbk, _ := simplifiedchinese.GBK.NewEncoder().String(body)
+		w.Header().Set("Content-Type", "application/json; charset=gbk")
+		w.Header().Set("Content-Encoding", "br")
+		writer := brotli.NewWriter(w)
+		_, _ = writer.Write([]byte(gbk))
+		_ = writer.Close()
+	}))
+	defer server.Close()
+	var response []byte
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+		EventCreate: func(_ context.Context) RestEvent {
+			return NewAppRestEvent(func(_ string, _ string, _ int, _ map[string][]string, _ []byte, res []byte, _ error) {
+				response = res
+			})
+		},
+	})
+	data := (<-client.NewApi(&testDome1{}).Do(context.Background(), test1, nil)).JsonResult("result")
+	if err := data.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if msg := data.GetData("message").String(); msg != "成功" {
+		t.Errorf("message wrong:%s", msg)
+	}
+	if string(response) != body {
+		t.Errorf("event must receive decoded body:%s", response)
+	}
+}
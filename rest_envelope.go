@@ -0,0 +1,76 @@
+package rest_client
+
+import (
+	"github.com/tidwall/gjson"
+)
+
+// RestEnvelope 返回内容外层结构规则,用于判断接口是否成功及提取错误信息
+// 如 {"code":0,"msg":"","data":{}} 可配置为:
+//
+//	&RestEnvelope{Success: map[string][]string{"code": {"0"}}, CodePath: "code", MessagePath: "msg", DataPath: "data"}
+type RestEnvelope struct {
+	Success     map[string][]string `json:"success" yaml:"success" validate:"required"` //成功条件,路径对应允许的值,全部满足时成功
+	CodePath    string              `json:"code_path" yaml:"code_path"`                 //错误码路径
+	SubCodePath string              `json:"sub_code_path" yaml:"sub_code_path"`         //子错误码路径
+	MessagePath string              `json:"message_path" yaml:"message_path"`           //错误信息路径,为空时使用返回内容
+	DataPath    string              `json:"data_path" yaml:"data_path"`                 //数据路径,JsonResult 未指定路径时作为 basePath
+}
+
+// AppRestEnvelope 内部接口默认的返回结构 {"result":{"code":"200","state":"ok","message":""}}
+var AppRestEnvelope = &RestEnvelope{
+	Success: map[string][]string{
+		"result.code":  {"200"},
+		"result.state": {"ok"},
+	},
+	CodePath:    "result.code",
+	SubCodePath: "result.state",
+	MessagePath: "result.message",
+}
+
+// success 是否满足全部成功条件
+func (envelope *RestEnvelope) success(body string) bool {
+	for path, values := range envelope.Success {
+		value := gjson.Get(body, path).String()
+		find := false
+		for _, tmp := range values {
+			if tmp == value {
+				find = true
+				break
+			}
+		}
+		if !find {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckJsonResult 检测返回内容,失败时返回 AppClientError
+func (envelope *RestEnvelope) CheckJsonResult(body string) error {
+	if envelope.success(body) {
+		return nil
+	}
+	var code, subCode, msg string
+	if len(envelope.CodePath) > 0 {
+		code = gjson.Get(body, envelope.CodePath).String()
+	}
+	if len(envelope.SubCodePath) > 0 {
+		subCode = gjson.Get(body, envelope.SubCodePath).String()
+	}
+	if len(envelope.MessagePath) > 0 {
+		msg = gjson.Get(body, envelope.MessagePath).String()
+	}
+	serverMsg := msg
+	if len(msg) == 0 {
+		msg = body
+	}
+	err := NewAppClientError(code, subCode, "server return fail:"+msg)
+	err.ServerMsg = serverMsg
+	return err
+}
+
+// WithEnvelope 设置返回内容外层结构规则, JsonResult 时代替 RestJsonResult 检测
+func (res *RestResult) WithEnvelope(envelope *RestEnvelope) *RestResult {
+	res.envelope = envelope
+	return res
+}
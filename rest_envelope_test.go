@@ -0,0 +1,61 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRestEnvelope(t *testing.T) {
+	if err := AppRestEnvelope.CheckJsonResult(`{"result":{"code":"200","state":"ok"}}`); err != nil {
+		t.Fatal(err)
+	}
+	err := AppRestEnvelope.CheckJsonResult(`{"result":{"code":"500","state":"fail","message":"wrong"}}`)
+	var aErr *AppClientError
+	if !errors.As(err, &aErr) || aErr.Code != "500" || aErr.SubCode != "fail" || aErr.Msg != "server return fail:wrong" {
+		t.Errorf("default envelope wrong:%v", err)
+	}
+
+	body := `{"code":0,"msg":"","data":{"name":"a"}}`
+	var envelope *RestEnvelope
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+		Envelope: &RestEnvelope{
+			Success:     map[string][]string{"code": {"0"}},
+			CodePath:    "code",
+			SubCodePath: "sub_code",
+			MessagePath: "msg",
+			DataPath:    "data",
+		},
+	})
+	api := client.NewApi(&testDome1{})
+	if name := (<-api.Do(context.Background(), test1, nil)).JsonResult().GetData("name").String(); name != "a" {
+		t.Errorf("envelope data path wrong:%s", name)
+	}
+	if name := (<-api.Do(context.Background(), test1, nil)).JsonResult("").GetData("data.name").String(); name != "a" {
+		t.Errorf("envelope path must be overridden:%s", name)
+	}
+	body = `{"code":1001,"sub_code":"stock","msg":"no stock"}`
+	err = (<-api.Do(context.Background(), test1, nil)).JsonResult().Err()
+	if !errors.As(err, &aErr) || aErr.Code != "1001" || aErr.SubCode != "stock" || aErr.Msg != "server return fail:no stock" {
+		t.Errorf("config envelope wrong:%v", err)
+	}
+
+	envelope = &RestEnvelope{Success: map[string][]string{"status": {"success", "partial"}}, MessagePath: "error"}
+	body = `{"status":"partial","list":[1]}`
+	res := NewRestBodyResult(&AppRestBuild{Envelope: envelope}, body, nil, nil).WithEnvelope(envelope)
+	if data := res.JsonResult(); data.Err() != nil || data.GetData("list.0").Int() != 1 {
+		t.Errorf("build envelope wrong:%v", data.Err())
+	}
+	if err := (&AppRestBuild{Envelope: envelope}).CheckJsonResult(`{"status":"fail","error":"e"}`); err == nil || err.Error() != "server return fail:e []" {
+		t.Errorf("build check wrong:%v", err)
+	}
+}
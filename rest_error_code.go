@@ -0,0 +1,110 @@
+package rest_client
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RestErrorCategory 错误分类,可通过 errors.Is(err, RestErrorAuth) 判断
+type RestErrorCategory string
+
+const (
+	RestErrorBusiness   RestErrorCategory = "business"   //业务错误
+	RestErrorAuth       RestErrorCategory = "auth"       //认证及权限错误
+	RestErrorThrottling RestErrorCategory = "throttling" //限流
+	RestErrorServer     RestErrorCategory = "server"     //服务端错误
+)
+
+func (category RestErrorCategory) Error() string {
+	return "rest error category:" + string(category)
+}
+
+// RestCodeError 服务错误码定义,注册后 CheckJsonResult 返回的 AppClientError 可通过 errors.Is 匹配
+//
+//	var ErrNoStock = &RestCodeError{Category: RestErrorBusiness, Code: "1001", Message: "库存不足"}
+//	manager.RegisterErrorCode("product", ErrNoStock)
+//	errors.Is(err, ErrNoStock)
+type RestCodeError struct {
+	Category  RestErrorCategory
+	Code      string
+	SubCode   string //为空时匹配任意子错误码
+	Retryable bool   //是否可重试
+	Message   string //面向用户的错误信息模板,可使用 {code} {sub_code} {message}
+}
+
+func (err *RestCodeError) Error() string {
+	return fmt.Sprintf("rest error code:%s sub_code:%s category:%s", err.Code, err.SubCode, err.Category)
+}
+
+// Is 匹配错误分类
+func (err *RestCodeError) Is(target error) bool {
+	category, ok := target.(RestErrorCategory)
+	return ok && category == err.Category
+}
+
+// RestErrorCodes 服务的错误码定义
+type RestErrorCodes []*RestCodeError
+
+// Find 查找错误码定义,优先匹配子错误码,不存在时返回nil
+func (codes RestErrorCodes) Find(code string, subCode string) *RestCodeError {
+	var find *RestCodeError
+	for _, tmp := range codes {
+		if tmp.Code != code {
+			continue
+		}
+		if tmp.SubCode == subCode {
+			return tmp
+		}
+		if len(tmp.SubCode) == 0 && find == nil {
+			find = tmp
+		}
+	}
+	return find
+}
+
+// define 为 AppClientError 设置错误码定义
+func (codes RestErrorCodes) define(err error) error {
+	var aErr *AppClientError
+	if len(codes) > 0 && errors.As(err, &aErr) && aErr.Define == nil {
+		aErr.Define = codes.Find(aErr.Code, aErr.SubCode)
+	}
+	return err
+}
+
+// RegisterErrorCode 按服务配置名注册错误码定义
+func (c *RestClientManager) RegisterErrorCode(configName string, codes ...*RestCodeError) {
+	c.tableLock.Lock()
+	defer c.tableLock.Unlock()
+	if c.errorCodes == nil {
+		c.errorCodes = make(map[string]RestErrorCodes)
+	}
+	c.errorCodes[configName] = append(c.errorCodes[configName], codes...)
+}
+
+// ErrorCodes 服务配置注册的错误码定义
+func (c *RestClientManager) ErrorCodes(configName string) RestErrorCodes {
+	c.tableLock.RLock()
+	defer c.tableLock.RUnlock()
+	return c.errorCodes[configName]
+}
+
+// WithErrorCodes 设置错误码定义, JsonResult 检测失败时为 AppClientError 设置匹配的定义
+func (res *RestResult) WithErrorCodes(codes RestErrorCodes) *RestResult {
+	res.errorCodes = codes
+	return res
+}
+
+// RestErrorRetryable 错误是否为可重试的注册错误码
+func RestErrorRetryable(err error) bool {
+	var cErr *RestCodeError
+	return errors.As(err, &cErr) && cErr.Retryable
+}
+
+// RestErrorUserMessage 面向用户的错误信息,非注册错误码时返回空字符串
+func RestErrorUserMessage(err error) string {
+	var aErr *AppClientError
+	if errors.As(err, &aErr) {
+		return aErr.UserMessage()
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRestErrorCode(t *testing.T) {
+	errNoStock := &RestCodeError{Category: RestErrorBusiness, Code: "1001", Message: "库存不足:{message}"}
+	errToken := &RestCodeError{Category: RestErrorAuth, Code: "401", SubCode: "token", Message: "请重新登录"}
+	errAuth := &RestCodeError{Category: RestErrorAuth, Code: "401"}
+	errLimit := &RestCodeError{Category: RestErrorThrottling, Code: "429", Retryable: true, Message: "请稍后重试 [{code}]"}
+	codes := RestErrorCodes{errNoStock, errAuth, errToken, errLimit}
+	if codes.Find("401", "token") != errToken || codes.Find("401", "other") != errAuth || codes.Find("500", "") != nil {
+		t.Error("error code find wrong")
+	}
+
+	body := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	client.RegisterErrorCode("test111", errNoStock, errAuth, errToken)
+	client.RegisterErrorCode("test111", errLimit)
+	api := client.NewApi(&testDome1{})
+	do := func(resBody string) error {
+		body = resBody
+		return (<-api.Do(context.Background(), test1, nil)).JsonResult().Err()
+	}
+
+	err := do(`{"result":{"code":"1001","state":"fail","message":"sku a"}}`)
+	if !errors.Is(err, errNoStock) || !errors.Is(err, RestErrorBusiness) || errors.Is(err, RestErrorAuth) {
+		t.Errorf("business error wrong:%v", err)
+	}
+	if RestErrorUserMessage(err) != "库存不足:sku a" || RestErrorRetryable(err) {
+		t.Errorf("business message wrong:%s", RestErrorUserMessage(err))
+	}
+	err = do(`{"result":{"code":"401","state":"token","message":"expired"}}`)
+	if !errors.Is(err, errToken) || errors.Is(err, errAuth) || !errors.Is(err, RestErrorAuth) {
+		t.Errorf("auth error wrong:%v", err)
+	}
+	err = do(`{"result":{"code":"429","state":"fail"}}`)
+	if !errors.Is(err, RestErrorThrottling) || !RestErrorRetryable(err) || RestErrorUserMessage(err) != "请稍后重试 [429]" {
+		t.Errorf("throttling error wrong:%v", err)
+	}
+	err = do(`{"result":{"code":"500","state":"fail"}}`)
+	var aErr *AppClientError
+	if !errors.As(err, &aErr) || aErr.Define != nil || errors.Is(err, RestErrorServer) || RestErrorUserMessage(err) != "" {
+		t.Errorf("unregistered error wrong:%v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package rest_client
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// RestFuture 异步请求结果
+// 可通过 Wait 等待结果,或通过 Done 在 select 中使用,取消或被回收时关闭未读取的返回内容
+type RestFuture struct {
+	done      chan struct{}
+	cancel    context.CancelFunc
+	lock      sync.Mutex
+	result    *RestResult
+	taken     bool
+	cancelled bool
+	callbacks []func(res *RestResult)
+}
+
+// DoFuture 执行请求并返回 RestFuture,参数同 Do
+func (client *RestClient) DoFuture(ctx context.Context, key interface{}, param interface{}) *RestFuture {
+	ctx, cancel := context.WithCancel(ctx)
+	future := &RestFuture{
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+	rc := client.Do(ctx, key, param)
+	go future.resolve(rc)
+	runtime.SetFinalizer(future, (*RestFuture).release)
+	return future
+}
+
+// resolve 接收请求结果并执行回调
+func (future *RestFuture) resolve(rc chan *RestResult) {
+	res := <-rc
+	future.lock.Lock()
+	if future.cancelled {
+		_ = res.Close()
+		res = NewRestResultFromError(context.Canceled, nil)
+	}
+	//请求上下文在返回内容关闭时结束,取走结果后 future 被回收不影响读取
+	res.onClose(future.cancel)
+	future.result = res
+	callbacks := future.callbacks
+	future.callbacks = nil
+	if len(callbacks) > 0 {
+		future.taken = true
+	}
+	close(future.done)
+	future.lock.Unlock()
+	for _, callback := range callbacks {
+		callback(res)
+	}
+}
+
+// release 未取走的结果关闭返回内容并结束请求上下文,已取走的结果由调用方关闭
+func (future *RestFuture) release() {
+	future.lock.Lock()
+	defer future.lock.Unlock()
+	if future.taken {
+		return
+	}
+	future.cancel()
+	if future.result != nil {
+		_ = future.result.Close()
+	}
+}
+
+// Done 请求完成时关闭的通道
+func (future *RestFuture) Done() <-chan struct{} {
+	return future.done
+}
+
+// Wait 等待请求结果, ctx 结束时返回错误结果,不影响请求本身
+func (future *RestFuture) Wait(ctx context.Context) *RestResult {
+	select {
+	case <-future.done:
+		future.lock.Lock()
+		defer future.lock.Unlock()
+		future.taken = true
+		return future.result
+	case <-ctx.Done():
+		return NewRestResultFromError(ctx.Err(), nil)
+	}
+}
+
+// Cancel 取消请求,已完成但未取走的结果关闭返回内容
+func (future *RestFuture) Cancel() {
+	future.lock.Lock()
+	defer future.lock.Unlock()
+	future.cancelled = true
+	future.cancel()
+	if future.result != nil && !future.taken {
+		_ = future.result.Close()
+		future.result = NewRestResultFromError(context.Canceled, nil)
+	}
+}
+
+// OnDone 注册完成回调,已完成时立即执行
+func (future *RestFuture) OnDone(callback func(res *RestResult)) {
+	future.lock.Lock()
+	if future.result == nil {
+		future.callbacks = append(future.callbacks, callback)
+		future.lock.Unlock()
+		return
+	}
+	future.taken = true
+	res := future.result
+	future.lock.Unlock()
+	callback(res)
+}
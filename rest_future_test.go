@@ -0,0 +1,157 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRestFuture(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"}}`))
+	}))
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	api := client.NewApi(&testDome1{})
+	future := api.DoFuture(context.Background(), test1, nil)
+	done := make(chan *RestResult, 1)
+	future.OnDone(func(res *RestResult) {
+		done <- res
+	})
+	select {
+	case <-future.Done():
+	case <-time.After(time.Second):
+		t.Fatal("future not done")
+	}
+	if err := future.Wait(context.Background()).JsonResult().Err(); err != nil {
+		t.Fatal(err)
+	}
+	if res := <-done; res == nil {
+		t.Error("callback not run")
+	}
+	called := false
+	future.OnDone(func(_ *RestResult) {
+		called = true
+	})
+	if !called {
+		t.Error("callback must run when done")
+	}
+}
+
+func TestRestFutureGC(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"code":"200",`))
+		w.(http.Flusher).Flush()
+		<-block
+		_, _ = w.Write([]byte(`"state":"ok"}}`))
+	}))
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	res := client.NewApi(&testDome1{}).DoFuture(context.Background(), test1, nil).Wait(context.Background())
+	//future 已不可达,回收时不能结束已取走结果的请求
+	for i := 0; i < 3; i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(block)
+	if err := res.JsonResult().Err(); err != nil {
+		t.Errorf("taken result must be readable after gc:%v", err)
+	}
+}
+
+func TestRestFutureCancel(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-block
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"}}`))
+	}))
+	defer server.Close()
+	defer close(block)
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	api := client.NewApi(&testDome1{})
+	future := api.DoFuture(context.Background(), test1, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := future.Wait(ctx).Err(); err != context.DeadlineExceeded {
+		t.Errorf("wait must timeout:%v", err)
+	}
+	future.Cancel()
+	select {
+	case <-future.Done():
+	case <-time.After(time.Second):
+		t.Fatal("cancel not finish request")
+	}
+	if err := future.Wait(context.Background()).Err(); err != context.Canceled {
+		t.Errorf("cancel result wrong:%v", err)
+	}
+}
+
+func TestAppRestBuildHeaderTimeout(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-block
+	}))
+	defer server.Close()
+	defer close(block)
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+		Builds: map[string]*RestBuildConfig{
+			"0": {Timeout: RestDuration(50 * time.Millisecond)},
+		},
+	})
+	err := (<-client.NewApi(&testDome1{}).Do(context.Background(), test1, nil)).Err()
+	if rErr, ok := err.(*RestClientError); !ok || rErr.Code != "13" {
+		t.Errorf("header timeout error wrong:%v", err)
+	}
+	if client.NewApi(&testDome1{}).GetTransport().ResponseHeaderTimeout != NewRestTransport().ResponseHeaderTimeout {
+		t.Error("transport must not change")
+	}
+}
+
+func TestAppRestBuildLongTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(150 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"}}`))
+	}))
+	defer server.Close()
+	transport := NewRestTransport()
+	transport.ResponseHeaderTimeout = 50 * time.Millisecond
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	client.SetTransport("test111", transport)
+	api := client.NewApi(&testDome1{})
+	if err := (<-api.Do(context.Background(), test1, nil)).JsonResult().Err(); err != nil {
+		t.Errorf("build timeout longer than transport must apply:%v", err)
+	}
+	if transport.ResponseHeaderTimeout != 50*time.Millisecond {
+		t.Error("transport must not change")
+	}
+	if _, ok := client.headerTransports.Load(transport); !ok {
+		t.Error("header transport must cache")
+	}
+	client.SetTransport("test111", NewRestTransport())
+	if _, ok := client.headerTransports.Load(transport); ok {
+		t.Error("unused header transport must close")
+	}
+}
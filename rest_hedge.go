@@ -0,0 +1,213 @@
+package rest_client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// hedgeMinSamples 按分位数计算等待时间需要的最少样本数
+const hedgeMinSamples = 20
+
+// hedgeMaxSamples 保留的最近耗时样本数
+const hedgeMaxSamples = 200
+
+// RestHedge 对冲请求配置,首次请求在等待时间内未返回时再发起请求,采用最先成功的结果
+// 只应用于幂等接口,按分位数计算等待时间时需复用同一个 RestHedge 实例
+type RestHedge struct {
+	Delay       time.Duration //发起下一次请求前的等待时间
+	Percentile  float64       //大于0时按历史耗时的分位数(如0.95)计算等待时间,样本不足时使用 Delay
+	MaxAttempts int           //最大请求次数,默认2
+	Urls        []string      //对冲请求使用的服务地址,依次轮换,为空时请求与首次相同的地址
+	lock        sync.Mutex
+	samples     []time.Duration
+	samplePos   int
+}
+
+// RestHedgeEvent 事件可选实现,用于接收对冲请求信息
+type RestHedgeEvent interface {
+	HedgeAttempt(attempt int, method, url string)   //发起对冲请求时回调,attempt 从1开始,首次请求为0
+	HedgeWinner(attempt int, latency time.Duration) //采用某次请求结果时回调
+}
+
+// delay 当前使用的等待时间
+func (hedge *RestHedge) delay() time.Duration {
+	if hedge.Percentile <= 0 {
+		return hedge.Delay
+	}
+	hedge.lock.Lock()
+	samples := make([]time.Duration, len(hedge.samples))
+	copy(samples, hedge.samples)
+	hedge.lock.Unlock()
+	if len(samples) < hedgeMinSamples {
+		return hedge.Delay
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i] < samples[j]
+	})
+	index := int(float64(len(samples)-1) * hedge.Percentile)
+	if index >= len(samples) {
+		index = len(samples) - 1
+	}
+	return samples[index]
+}
+
+// observe 记录成功请求的耗时
+func (hedge *RestHedge) observe(latency time.Duration) {
+	hedge.lock.Lock()
+	defer hedge.lock.Unlock()
+	if len(hedge.samples) < hedgeMaxSamples {
+		hedge.samples = append(hedge.samples, latency)
+		return
+	}
+	hedge.samples[hedge.samplePos] = latency
+	hedge.samplePos = (hedge.samplePos + 1) % hedgeMaxSamples
+}
+
+type hedgeResponse struct {
+	attempt  int
+	response *http.Response
+	err      error
+	cancel   context.CancelFunc
+}
+
+// hedgeBody 采用结果的BODY,关闭时释放该次请求的上下文
+type hedgeBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (body *hedgeBody) Close() error {
+	defer body.cancel()
+	return body.ReadCloser.Close()
+}
+
+// Do 执行对冲请求,请求需无BODY
+// @param urls 对冲请求使用的完整地址,为空时使用 req 的地址
+// @param event 可以为nil
+func (hedge *RestHedge) Do(ctx context.Context, client *http.Client, req *http.Request, urls []string, event RestEvent) (*http.Response, error) {
+	maxAttempts := hedge.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 2
+	}
+	hEvent, _ := event.(RestHedgeEvent)
+	start := time.Now()
+	responses := make(chan *hedgeResponse, maxAttempts)
+	cancels := make(map[int]context.CancelFunc, maxAttempts)
+	launch := func(attempt int) error {
+		attemptReq := req
+		if attempt > 0 && len(urls) > 0 {
+			hedgeUrl, err := url.Parse(urls[(attempt-1)%len(urls)])
+			if err != nil {
+				return err
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.URL = hedgeUrl
+			attemptReq.Host = ""
+		}
+		attemptCtx, cancel := context.WithCancel(ctx)
+		if bulkhead := restBulkheadFrom(ctx); bulkhead != nil && attempt > 0 {
+			//对冲请求各自占用执行位置,没有空闲位置时不发起
+			if !bulkhead.TryAcquire() {
+				cancel()
+				return NewRestClientError("4", "bulkhead is full, skip hedge")
+			}
+			var once sync.Once
+			attemptCancel := cancel
+			cancel = func() {
+				attemptCancel()
+				once.Do(bulkhead.Release)
+			}
+		}
+		if attempt > 0 && hEvent != nil {
+			hEvent.HedgeAttempt(attempt, attemptReq.Method, attemptReq.URL.String())
+		}
+		cancels[attempt] = cancel
+		attemptReq = attemptReq.WithContext(attemptCtx)
+		go func() {
+			res, err := client.Do(attemptReq)
+			responses <- &hedgeResponse{
+				attempt:  attempt,
+				response: res,
+				err:      err,
+				cancel:   cancel,
+			}
+		}()
+		return nil
+	}
+	if err := launch(0); err != nil {
+		return nil, err
+	}
+	next, pending := 1, 1
+	timer := time.NewTimer(hedge.delay())
+	defer timer.Stop()
+	var last *hedgeResponse
+	for pending > 0 {
+		var hedgeTimer <-chan time.Time
+		if next < maxAttempts {
+			hedgeTimer = timer.C
+		}
+		select {
+		case <-hedgeTimer:
+			if err := launch(next); err == nil {
+				pending++
+			}
+			next++
+			timer.Reset(hedge.delay())
+		case res := <-responses:
+			pending--
+			if res.err == nil && res.response.StatusCode < http.StatusInternalServerError {
+				for attempt, cancel := range cancels {
+					if attempt != res.attempt {
+						cancel()
+					}
+				}
+				go hedgeDiscard(responses, pending)
+				latency := time.Since(start)
+				hedge.observe(latency)
+				if hEvent != nil {
+					hEvent.HedgeWinner(res.attempt, latency)
+				}
+				res.response.Body = &hedgeBody{ReadCloser: res.response.Body, cancel: res.cancel}
+				return res.response, nil
+			}
+			if last != nil {
+				hedgeClose(last)
+			}
+			last = res
+			if pending == 0 && next < maxAttempts {
+				if err := launch(next); err == nil {
+					pending++
+				}
+				next++
+			}
+		}
+	}
+	if last.err == nil {
+		if hEvent != nil {
+			hEvent.HedgeWinner(last.attempt, time.Since(start))
+		}
+		last.response.Body = &hedgeBody{ReadCloser: last.response.Body, cancel: last.cancel}
+		return last.response, nil
+	}
+	last.cancel()
+	return nil, last.err
+}
+
+// hedgeDiscard 关闭未采用请求的BODY
+func hedgeDiscard(responses chan *hedgeResponse, pending int) {
+	for i := 0; i < pending; i++ {
+		hedgeClose(<-responses)
+	}
+}
+
+func hedgeClose(res *hedgeResponse) {
+	if res.err == nil {
+		_ = res.response.Body.Close()
+	}
+	res.cancel()
+}
@@ -0,0 +1,150 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testHedgeEvent struct {
+	RestEventNoop
+	attempts []string
+	winner   int
+}
+
+func (event *testHedgeEvent) HedgeAttempt(_ int, _, url string) {
+	event.attempts = append(event.attempts, url)
+}
+
+func (event *testHedgeEvent) HedgeWinner(attempt int, _ time.Duration) {
+	event.winner = attempt
+}
+
+type testHedgeApi struct {
+	testDome1
+	hedge *RestHedge
+}
+
+func (res *testHedgeApi) ConfigBuilds(_ context.Context) (map[int]RestBuild, error) {
+	return map[int]RestBuild{
+		test1: &AppRestBuild{
+			HttpMethod: http.MethodGet,
+			Path:       "/detail",
+			Method:     "detail",
+			Hedge:      res.hedge,
+		},
+	}, nil
+}
+
+func TestRestHedge(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"from":"slow"}`))
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"from":"fast"}`))
+	}))
+	defer fast.Close()
+	event := &testHedgeEvent{winner: -1}
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: slow.URL,
+		EventCreate: func(_ context.Context) RestEvent {
+			return event
+		},
+	})
+	hedge := &RestHedge{
+		Delay: 20 * time.Millisecond,
+		Urls:  []string{fast.URL},
+	}
+	data := (<-client.NewApi(&testHedgeApi{hedge: hedge}).Do(context.Background(), test1, nil)).JsonResult()
+	if data.Err() != nil {
+		t.Fatal(data.Err())
+	}
+	if data.GetData("from").String() != "fast" {
+		t.Error("hedge request not win")
+	}
+	if event.winner != 1 || len(event.attempts) != 1 {
+		t.Error("hedge event wrong")
+	}
+}
+
+func TestRestHedgeDelay(t *testing.T) {
+	hedge := &RestHedge{
+		Delay:      time.Second,
+		Percentile: 0.9,
+	}
+	for i := 1; i < hedgeMinSamples; i++ {
+		hedge.observe(time.Duration(i) * time.Millisecond)
+	}
+	if hedge.delay() != time.Second {
+		t.Error("not enough samples must use delay")
+	}
+	hedge.observe(hedgeMinSamples * time.Millisecond)
+	if hedge.delay() != 18*time.Millisecond {
+		t.Error("percentile delay wrong", hedge.delay())
+	}
+}
+
+func TestRestHedgeBulkhead(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"from":"slow"}`))
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"from":"fast"}`))
+	}))
+	defer fast.Close()
+	for _, tCase := range []struct {
+		max  int
+		from string
+	}{
+		{max: 1, from: "slow"},
+		{max: 2, from: "fast"},
+	} {
+		event := &testHedgeEvent{winner: -1}
+		client := NewRestClientManager()
+		client.SetRestConfig(&AppRestConfig{
+			Name:   "test111",
+			AppUrl: slow.URL,
+			EventCreate: func(_ context.Context) RestEvent {
+				return event
+			},
+		})
+		bulkhead := NewRestBulkhead(tCase.max, 0, 0)
+		client.SetBulkhead("test111", bulkhead)
+		hedge := &RestHedge{
+			Delay: 20 * time.Millisecond,
+			Urls:  []string{fast.URL},
+		}
+		data := (<-client.NewApi(&testHedgeApi{hedge: hedge}).Do(context.Background(), test1, nil)).JsonResult()
+		if data.Err() != nil {
+			t.Fatal(data.Err())
+		}
+		if data.GetData("from").String() != tCase.from {
+			t.Errorf("max %d: hedge result wrong", tCase.max)
+		}
+		if tCase.max == 1 && len(event.attempts) != 0 {
+			t.Error("hedge must skip without free bulkhead slot")
+		}
+		for i := 0; bulkhead.InFlight() != 0 && i < 100; i++ {
+			time.Sleep(5 * time.Millisecond)
+		}
+		if bulkhead.InFlight() != 0 {
+			t.Errorf("max %d: bulkhead slot not released", tCase.max)
+		}
+	}
+}
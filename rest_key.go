@@ -0,0 +1,222 @@
+package rest_client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// RestKey 接口KEY,兼容 int 常量及命名KEY
+// 命名KEY可为 string 及自定义字符串类型,或实现 fmt.Stringer 的整数类型(如 type ProductKey int)
+type RestKey struct {
+	id    int
+	name  string
+	named bool
+	typ   reflect.Type
+}
+
+// NewRestKey 创建接口KEY
+func NewRestKey(key interface{}) (RestKey, error) {
+	switch tKey := key.(type) {
+	case RestKey:
+		return tKey, nil
+	case int:
+		return restIntKey(tKey), nil
+	case string:
+		return RestKey{name: tKey, named: true, typ: reflect.TypeOf(tKey)}, nil
+	}
+	val := reflect.ValueOf(key)
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if val.Int() > math.MaxInt || val.Int() < math.MinInt {
+			return RestKey{}, NewRestClientError("2", fmt.Sprintf("rest api key out of range:%v", key))
+		}
+		restKey := RestKey{id: int(val.Int()), name: strconv.FormatInt(val.Int(), 10), typ: val.Type()}
+		if str, ok := key.(fmt.Stringer); ok {
+			restKey.name = str.String()
+		}
+		return restKey, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if val.Uint() > math.MaxInt {
+			return RestKey{}, NewRestClientError("2", fmt.Sprintf("rest api key out of range:%v", key))
+		}
+		restKey := RestKey{id: int(val.Uint()), name: strconv.FormatUint(val.Uint(), 10), typ: val.Type()}
+		if str, ok := key.(fmt.Stringer); ok {
+			restKey.name = str.String()
+		}
+		return restKey, nil
+	case reflect.String:
+		restKey := RestKey{name: val.String(), named: true, typ: val.Type()}
+		if str, ok := key.(fmt.Stringer); ok {
+			restKey.name = str.String()
+		}
+		return restKey, nil
+	}
+	if str, ok := key.(fmt.Stringer); ok {
+		return RestKey{name: str.String(), named: true, typ: val.Type()}, nil
+	}
+	return RestKey{}, NewRestClientError("2", fmt.Sprintf("rest api key not support:%v", key))
+}
+
+// Int 整数KEY的值,命名KEY为0
+func (key RestKey) Int() int {
+	return key.id
+}
+
+// Named 是否为字符串KEY,字符串KEY通过 RestNamedApi 查找接口
+func (key RestKey) Named() bool {
+	return key.named
+}
+
+// Type 创建KEY时的原始类型
+func (key RestKey) Type() reflect.Type {
+	return key.typ
+}
+
+// restIntKey 创建 int 常量KEY
+func restIntKey(key int) RestKey {
+	return RestKey{id: key, name: strconv.Itoa(key), typ: reflect.TypeOf(key)}
+}
+
+// restKeyIndex 按接口KEY注册内容时使用的索引,自定义整数类型KEY与其 int 值相同
+type restKeyIndex struct {
+	named bool
+	name  string
+	id    int
+}
+
+// index 注册及查找使用的索引
+func (key RestKey) index() restKeyIndex {
+	if key.named {
+		return restKeyIndex{named: true, name: key.name}
+	}
+	return restKeyIndex{id: key.id}
+}
+
+// names 配置覆盖中可使用的名称,整数类型KEY可使用名称或数值
+func (key RestKey) names() []string {
+	if key.named {
+		return []string{key.name}
+	}
+	id := strconv.Itoa(key.id)
+	if key.name == id {
+		return []string{id}
+	}
+	return []string{key.name, id}
+}
+
+// typed 是否为自定义类型的KEY, int 及 string 不限定类型
+func (key RestKey) typed() bool {
+	return key.typ != nil && key.typ != reflect.TypeOf(0) && key.typ != reflect.TypeOf("")
+}
+
+// String KEY名称,用于日志、错误信息及配置覆盖, int 常量为其数值
+func (key RestKey) String() string {
+	return key.name
+}
+
+// RestNamedApi 使用字符串KEY的接口定义, ConfigBuilds 可返回nil
+type RestNamedApi interface {
+	RestApi
+	NamedConfigBuilds(ctx context.Context) (map[string]RestBuild, error)
+}
+
+// RestKeyTypeApi 限定KEY类型的接口定义,传入其他自定义类型的KEY时返回错误,防止混用不同服务的常量
+type RestKeyTypeApi interface {
+	RestApi
+	KeyType() reflect.Type
+}
+
+// newApiRestKey 创建接口KEY,接口限定了整数KEY类型时 int 常量转为该类型,使名称与类型KEY一致
+func newApiRestKey(api RestApi, key interface{}) (RestKey, error) {
+	restKey, err := NewRestKey(key)
+	if err != nil || restKey.named || restKey.typed() {
+		return restKey, err
+	}
+	tApi, ok := api.(RestKeyTypeApi)
+	if !ok {
+		return restKey, nil
+	}
+	keyType := tApi.KeyType()
+	if keyType == nil {
+		return restKey, nil
+	}
+	switch keyType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		val := reflect.New(keyType).Elem()
+		if val.OverflowInt(int64(restKey.id)) {
+			return restKey, nil
+		}
+		val.SetInt(int64(restKey.id))
+		return NewRestKey(val.Interface())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if restKey.id < 0 {
+			return restKey, nil
+		}
+		val := reflect.New(keyType).Elem()
+		if val.OverflowUint(uint64(restKey.id)) {
+			return restKey, nil
+		}
+		val.SetUint(uint64(restKey.id))
+		return NewRestKey(val.Interface())
+	}
+	return restKey, nil
+}
+
+// RestKeyBuild RestBuild 可选实现,需要命名KEY或自定义类型KEY时实现,实现后 Do 调用 BuildKeyRequest 代替 BuildRequest
+type RestKeyBuild interface {
+	BuildKeyRequest(ctx context.Context, config *RestClient, key RestKey, param interface{}, callerInfo *RestCallerInfo) *RestResult
+}
+
+// RestKeyEvent 事件可选实现,用于接收本次请求的接口KEY
+type RestKeyEvent interface {
+	RequestKey(key RestKey)
+}
+
+// findBuild 查找KEY对应的接口,已注册接口表时从接口表查找
+func (client *RestClient) findBuild(ctx context.Context, key RestKey) (RestBuild, error) {
+	if tApi, ok := client.Api.(RestKeyTypeApi); ok && key.typed() && key.typ != tApi.KeyType() {
+		return nil, NewRestClientError("2", "rest api key type is wrong:"+key.typ.String()+" "+key.String())
+	}
+	table := client.manager.getTable(client.Api)
+	var build RestBuild
+	var find bool
+	if key.named {
+		nApi, ok := client.Api.(RestNamedApi)
+		if !ok {
+			return nil, NewRestClientError("2", "rest api not support named key:"+key.String())
+		}
+		builds := map[string]RestBuild(nil)
+		if table != nil {
+			builds = table.named
+		} else {
+			var err error
+			if builds, err = nApi.NamedConfigBuilds(ctx); err != nil {
+				return nil, err
+			}
+		}
+		build, find = builds[key.name]
+	} else {
+		builds := map[int]RestBuild(nil)
+		if table != nil {
+			builds = table.builds
+		} else {
+			var err error
+			if builds, err = client.Api.ConfigBuilds(ctx); err != nil {
+				return nil, err
+			}
+		}
+		build, find = builds[key.id]
+	}
+	if !find {
+		return nil, NewRestClientError("2", "not find rest api:"+key.String())
+	}
+	if table != nil {
+		if oApi, ok := client.Api.(RestBuildOverride); ok {
+			return oApi.OverrideBuild(ctx, key, build)
+		}
+	}
+	return build, nil
+}
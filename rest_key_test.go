@@ -0,0 +1,210 @@
+package rest_client
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testKey int
+
+const (
+	testKeyDetail testKey = iota
+	testKeyAdd    testKey = iota
+)
+
+func (key testKey) String() string {
+	switch key {
+	case testKeyDetail:
+		return "testKeyDetail"
+	case testKeyAdd:
+		return "testKeyAdd"
+	}
+	return "testKey"
+}
+
+type otherKey int
+
+type testUint64Key uint64
+
+func (key testUint64Key) String() string {
+	return "uint64Key" + strconv.FormatUint(uint64(key), 10)
+}
+
+type testStringKey string
+
+type testKeyApi struct {
+	testDome1
+}
+
+func (res *testKeyApi) ConfigBuilds(_ context.Context) (map[int]RestBuild, error) {
+	return map[int]RestBuild{
+		int(testKeyDetail): &AppRestBuild{
+			HttpMethod: http.MethodGet,
+			Path:       "/detail",
+		},
+	}, nil
+}
+
+func (res *testKeyApi) NamedConfigBuilds(_ context.Context) (map[string]RestBuild, error) {
+	return map[string]RestBuild{
+		"detail": &AppRestBuild{
+			HttpMethod: http.MethodGet,
+			Path:       "/detail",
+		},
+	}, nil
+}
+
+func (res *testKeyApi) KeyType() reflect.Type {
+	return reflect.TypeOf(testKey(0))
+}
+
+func TestNewRestKey(t *testing.T) {
+	key, err := NewRestKey(testKeyAdd)
+	if err != nil || key.String() != "testKeyAdd" || key.Int() != 1 || key.Named() {
+		t.Error("stringer key wrong")
+	}
+	key, _ = NewRestKey(test2)
+	if key.String() != "1" || key.Int() != 1 {
+		t.Error("int key wrong")
+	}
+	key, _ = NewRestKey("detail")
+	if !key.Named() || key.String() != "detail" {
+		t.Error("string key wrong")
+	}
+	if _, err := NewRestKey(1.1); err == nil {
+		t.Error("float key not support")
+	}
+	key, err = NewRestKey(testUint64Key(3))
+	if err != nil || key.Named() || key.Int() != 3 || key.String() != "uint64Key3" {
+		t.Error("uint64 stringer key wrong")
+	}
+	key, err = NewRestKey(uintptr(4))
+	if err != nil || key.Named() || key.Int() != 4 {
+		t.Error("uintptr key wrong")
+	}
+	key, err = NewRestKey(testStringKey("detail"))
+	if err != nil || !key.Named() || key.String() != "detail" {
+		t.Error("custom string key wrong")
+	}
+	if _, err := NewRestKey(uint64(math.MaxUint64)); err == nil {
+		t.Error("out of range key must fail")
+	}
+}
+
+func TestRestClientKey(t *testing.T) {
+	var logKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"}}`))
+	}))
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+		EventCreate: func(_ context.Context) RestEvent {
+			return NewAppRestKeyEvent(func(key string, _ string, _ string, _ int, _ map[string][]string, _ []byte, _ []byte, _ error) {
+				logKey = key
+			})
+		},
+	})
+	api := client.NewApi(&testKeyApi{})
+	if err := (<-api.Do(context.Background(), testKeyDetail, nil)).JsonResult().Err(); err != nil {
+		t.Fatal(err)
+	}
+	if logKey != "testKeyDetail" {
+		t.Error("event key wrong")
+	}
+	if err := (<-api.Do(context.Background(), "detail", nil)).JsonResult().Err(); err != nil {
+		t.Fatal(err)
+	}
+	if logKey != "detail" {
+		t.Error("event named key wrong")
+	}
+	err := (<-api.Do(context.Background(), testKeyAdd, nil)).Err()
+	if err == nil || !strings.Contains(err.Error(), "testKeyAdd") {
+		t.Error("not find error must contain key name")
+	}
+	if err := (<-api.Do(context.Background(), otherKey(0), nil)).Err(); err == nil {
+		t.Error("other key type must fail")
+	}
+	if err := (<-client.NewApi(&testDome1{}).Do(context.Background(), "detail", nil)).Err(); err == nil {
+		t.Error("named key not support")
+	}
+}
+
+type testLegacyBuild struct {
+	key int
+}
+
+func (build *testLegacyBuild) BuildRequest(_ context.Context, _ *RestClient, key int, _ interface{}, _ *RestCallerInfo) *RestResult {
+	build.key = key
+	return NewRestBodyResult(build, `{}`, nil, nil)
+}
+
+type testLegacyApi struct {
+	testDome1
+	build *testLegacyBuild
+}
+
+func (res *testLegacyApi) ConfigBuilds(_ context.Context) (map[int]RestBuild, error) {
+	return map[int]RestBuild{
+		int(testKeyAdd): res.build,
+	}, nil
+}
+
+func TestRestKeyLegacyBuild(t *testing.T) {
+	build := &testLegacyBuild{key: -1}
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{Name: "test111"})
+	if err := (<-client.NewApi(&testLegacyApi{build: build}).Do(context.Background(), testKeyAdd, nil)).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if build.key != int(testKeyAdd) {
+		t.Error("legacy build key wrong")
+	}
+}
+
+func TestRestKeyNormalize(t *testing.T) {
+	api := &testKeyApi{}
+	intKey, _ := newApiRestKey(api, int(testKeyDetail))
+	typedKey, _ := newApiRestKey(api, testKeyDetail)
+	if intKey != typedKey || intKey.String() != "testKeyDetail" {
+		t.Error("int key must convert to api key type")
+	}
+	if key, _ := newApiRestKey(&testDome1{}, 0); key.String() != "0" {
+		t.Error("int key without key type wrong")
+	}
+	for _, builds := range []map[string]*RestBuildConfig{
+		{"0": {Timeout: RestDuration(time.Second)}},
+		{"testKeyDetail": {Timeout: RestDuration(time.Second)}},
+	} {
+		config := &AppRestConfig{Builds: builds}
+		intTimeout, _ := restBuildLimit(config, intKey, 0, 0)
+		typedTimeout, _ := restBuildLimit(config, typedKey, 0, 0)
+		if intTimeout != time.Second || typedTimeout != time.Second {
+			t.Error("build override must match key name and value")
+		}
+	}
+
+	client := NewRestClientManager()
+	schema := MustJsonSchema(`{"type":"object"}`)
+	if err := client.RegisterJsonSchema(api, testKeyDetail, schema, false); err != nil {
+		t.Fatal(err)
+	}
+	if client.NewApi(api).JsonSchema(int(testKeyDetail)) != schema {
+		t.Error("schema registered by typed key must find by int key")
+	}
+	if err := client.RegisterJsonSchema(&testDome1{}, 1, schema, false); err != nil {
+		t.Fatal(err)
+	}
+	if client.NewApi(&testDome1{}).JsonSchema(testKeyAdd) != schema {
+		t.Error("schema registered by int key must find by typed key")
+	}
+}
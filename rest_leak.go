@@ -0,0 +1,74 @@
+package rest_client
+
+import (
+	"sync"
+)
+
+// RestLeakDetector 未关闭结果检测,用于测试中发现未读取也未关闭的 RestResult
+type RestLeakDetector struct {
+	lock    sync.Mutex
+	results map[*RestResult]struct{}
+}
+
+// NewRestLeakDetector 创建未关闭结果检测,通过 RestClientManager.SetLeakDetector 启用
+func NewRestLeakDetector() *RestLeakDetector {
+	return &RestLeakDetector{
+		results: make(map[*RestResult]struct{}),
+	}
+}
+
+func (detector *RestLeakDetector) track(res *RestResult) {
+	detector.lock.Lock()
+	defer detector.lock.Unlock()
+	res.detector = detector
+	detector.results[res] = struct{}{}
+}
+
+func (detector *RestLeakDetector) untrack(res *RestResult) {
+	detector.lock.Lock()
+	defer detector.lock.Unlock()
+	delete(detector.results, res)
+}
+
+// Leaks 当前未关闭结果的调用方信息
+func (detector *RestLeakDetector) Leaks() []*RestCallerInfo {
+	detector.lock.Lock()
+	defer detector.lock.Unlock()
+	var callers []*RestCallerInfo
+	for res := range detector.results {
+		caller := res.caller
+		if caller == nil {
+			caller = &RestCallerInfo{}
+		}
+		callers = append(callers, caller)
+	}
+	return callers
+}
+
+// RestLeakT 测试接口,兼容 *testing.T
+type RestLeakT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Check 存在未关闭结果时报告测试错误
+func (detector *RestLeakDetector) Check(t RestLeakT) {
+	t.Helper()
+	for _, caller := range detector.Leaks() {
+		t.Errorf("rest result not closed: %s:%s %s", caller.FileName, caller.FileLine, caller.FuncName)
+	}
+}
+
+// SetLeakDetector 设置未关闭结果检测,传入nil时关闭检测
+func (c *RestClientManager) SetLeakDetector(detector *RestLeakDetector) {
+	c.detector.Store(&detector)
+}
+
+// trackResult 记录带返回内容的结果
+func (c *RestClientManager) trackResult(res *RestResult) {
+	detector, ok := c.detector.Load().(**RestLeakDetector)
+	if !ok || *detector == nil || res.closed || res.response == nil || res.response.Body == nil {
+		return
+	}
+	(*detector).track(res)
+}
@@ -0,0 +1,175 @@
+package rest_client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testLeakT struct {
+	errors []string
+}
+
+func (t *testLeakT) Helper() {}
+
+func (t *testLeakT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestRestResultClose(t *testing.T) {
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"}}`))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	detector := NewRestLeakDetector()
+	client.SetLeakDetector(detector)
+	api := client.NewApi(&testDome1{})
+
+	res := <-api.Do(context.Background(), test1, nil)
+	if res.Err() != nil {
+		t.Fatal(res.Err())
+	}
+	leakT := &testLeakT{}
+	detector.Check(leakT)
+	if len(leakT.errors) != 1 || len(detector.Leaks()) != 1 {
+		t.Fatal("not closed result must report")
+	}
+	if caller := detector.Leaks()[0]; !strings.HasSuffix(caller.FileName, "rest_leak_test.go") ||
+		!strings.HasSuffix(caller.FuncName, "TestRestResultClose") {
+		t.Errorf("leak caller wrong:%s %s", caller.FileName, caller.FuncName)
+	}
+	if err := res.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := res.Close(); err != nil {
+		t.Error("close twice must ok")
+	}
+	if len(detector.Leaks()) != 0 {
+		t.Error("closed result must not report")
+	}
+	if _, err := res.Read(make([]byte, 10)); err == nil {
+		t.Error("read closed result must fail")
+	}
+
+	if err := (<-api.Do(context.Background(), test1, nil)).JsonResult().Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(detector.Leaks()) != 0 {
+		t.Error("json result must auto close")
+	}
+	if atomic.LoadInt32(&conns) != 1 {
+		t.Errorf("connection must reuse, new conns %d", conns)
+	}
+	client.SetLeakDetector(nil)
+	res = <-api.Do(context.Background(), test1, nil)
+	if len(detector.Leaks()) != 0 {
+		t.Error("detector disabled")
+	}
+	_ = res.Close()
+}
+
+func TestRestResultReadAfterEOF(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"}}`))
+	}))
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	api := client.NewApi(&testDome1{})
+	res := <-api.Do(context.Background(), test1, nil)
+	if res.Err() != nil {
+		t.Fatal(res.Err())
+	}
+	body, err := ioutil.ReadAll(res)
+	if err != nil || len(body) == 0 {
+		t.Fatal(err)
+	}
+	if n, err := res.Read(make([]byte, 10)); n != 0 || err != io.EOF {
+		t.Errorf("read after eof must return io.EOF, got %v", err)
+	}
+}
+
+func TestRestLeakCaller(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"}}`))
+	}))
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	detector := NewRestLeakDetector()
+	client.SetLeakDetector(detector)
+	api := client.NewApi(&testDome1{})
+	future := api.DoFuture(context.Background(), test1, nil)
+	res := future.Wait(context.Background())
+	defer res.Close()
+	batch := DoBatch(context.Background(), []*RestBatchCall{api.Call(test1, nil)})
+	defer batch.Cancel()
+	leaks := detector.Leaks()
+	if len(leaks) != 2 {
+		t.Fatalf("leak count wrong:%d", len(leaks))
+	}
+	for _, caller := range leaks {
+		if !strings.HasSuffix(caller.FileName, "rest_leak_test.go") || !strings.HasSuffix(caller.FuncName, "TestRestLeakCaller") {
+			t.Errorf("leak caller wrong:%s %s", caller.FileName, caller.FuncName)
+		}
+	}
+}
+
+func TestRestResultFinalizer(t *testing.T) {
+	var conns int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"}}`))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	api := client.NewApi(&testDome1{})
+	for i := 0; i < 3; i++ {
+		if err := (<-api.Do(context.Background(), test1, nil)).Err(); err != nil {
+			t.Fatal(err)
+		}
+		for j := 0; j < 3; j++ {
+			runtime.GC()
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	if atomic.LoadInt32(&conns) != 1 {
+		t.Errorf("abandoned result must close and reuse connection, new conns %d", conns)
+	}
+}
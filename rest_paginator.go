@@ -0,0 +1,310 @@
+package rest_client
+
+import (
+	"context"
+)
+
+// RestPageStrategy 分页方式,生成每页的请求参数
+type RestPageStrategy interface {
+	// First 第一页的请求参数, param 为基础参数的副本
+	First(param map[string]interface{}) map[string]interface{}
+	// Next 根据当前页结果生成下一页的请求参数,没有下一页时返回nil
+	// @param count 当前页的数据条数
+	Next(param map[string]interface{}, page *JsonResult, count int) map[string]interface{}
+}
+
+// restPageCopy 复制请求参数并设置分页参数
+func restPageCopy(param map[string]interface{}, kv ...interface{}) map[string]interface{} {
+	tmp := make(map[string]interface{}, len(param)+len(kv)/2)
+	for key, val := range param {
+		tmp[key] = val
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		tmp[kv[i].(string)] = kv[i+1]
+	}
+	return tmp
+}
+
+// restPageInt 获取分页参数中的整数
+func restPageInt(param map[string]interface{}, key string) int {
+	if val, ok := param[key].(int); ok {
+		return val
+	}
+	return 0
+}
+
+// restPageMore 按总数及当前页数量判断是否还有下一页
+func restPageMore(page *JsonResult, totalPath string, loaded int, count int, size int) bool {
+	if count == 0 || count < size {
+		return false
+	}
+	if len(totalPath) > 0 {
+		if total := page.GetData(totalPath); total.Err() == nil && total.Exists() {
+			return int64(loaded) < total.Int()
+		}
+	}
+	return true
+}
+
+// RestPageNumber 按页码分页
+type RestPageNumber struct {
+	PageKey   string //页码参数名,默认 page
+	SizeKey   string //每页数量参数名,默认 page_size
+	Size      int    //每页数量,默认 20
+	TotalPath string //返回总数的路径,如 data.total,未设置或不存在时按当前页数量判断是否有下一页
+}
+
+func (page *RestPageNumber) keys() (string, string, int) {
+	pageKey, sizeKey, size := page.PageKey, page.SizeKey, page.Size
+	if len(pageKey) == 0 {
+		pageKey = "page"
+	}
+	if len(sizeKey) == 0 {
+		sizeKey = "page_size"
+	}
+	if size <= 0 {
+		size = 20
+	}
+	return pageKey, sizeKey, size
+}
+
+func (page *RestPageNumber) First(param map[string]interface{}) map[string]interface{} {
+	pageKey, sizeKey, size := page.keys()
+	return restPageCopy(param, pageKey, 1, sizeKey, size)
+}
+
+func (page *RestPageNumber) Next(param map[string]interface{}, res *JsonResult, count int) map[string]interface{} {
+	pageKey, _, size := page.keys()
+	num := restPageInt(param, pageKey)
+	if !restPageMore(res, page.TotalPath, num*size, count, size) {
+		return nil
+	}
+	return restPageCopy(param, pageKey, num+1)
+}
+
+// RestPageOffset 按偏移量分页
+type RestPageOffset struct {
+	OffsetKey string //偏移量参数名,默认 offset
+	LimitKey  string //每页数量参数名,默认 limit
+	Limit     int    //每页数量,默认 20
+	TotalPath string //返回总数的路径,如 data.total,未设置或不存在时按当前页数量判断是否有下一页
+}
+
+func (page *RestPageOffset) keys() (string, string, int) {
+	offsetKey, limitKey, limit := page.OffsetKey, page.LimitKey, page.Limit
+	if len(offsetKey) == 0 {
+		offsetKey = "offset"
+	}
+	if len(limitKey) == 0 {
+		limitKey = "limit"
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	return offsetKey, limitKey, limit
+}
+
+func (page *RestPageOffset) First(param map[string]interface{}) map[string]interface{} {
+	offsetKey, limitKey, limit := page.keys()
+	return restPageCopy(param, offsetKey, 0, limitKey, limit)
+}
+
+func (page *RestPageOffset) Next(param map[string]interface{}, res *JsonResult, count int) map[string]interface{} {
+	offsetKey, _, limit := page.keys()
+	offset := restPageInt(param, offsetKey) + count
+	if !restPageMore(res, page.TotalPath, offset, count, limit) {
+		return nil
+	}
+	return restPageCopy(param, offsetKey, offset)
+}
+
+// RestPageCursor 按游标分页,返回的游标为空时结束
+type RestPageCursor struct {
+	CursorKey  string //游标参数名,默认 cursor
+	CursorPath string //返回下一页游标的路径,默认 data.cursor
+	LimitKey   string //每页数量参数名,默认 limit,Limit 为0时不传
+	Limit      int    //每页数量
+}
+
+func (page *RestPageCursor) First(param map[string]interface{}) map[string]interface{} {
+	if page.Limit <= 0 {
+		return restPageCopy(param)
+	}
+	limitKey := page.LimitKey
+	if len(limitKey) == 0 {
+		limitKey = "limit"
+	}
+	return restPageCopy(param, limitKey, page.Limit)
+}
+
+func (page *RestPageCursor) Next(param map[string]interface{}, res *JsonResult, count int) map[string]interface{} {
+	cursorKey, cursorPath := page.CursorKey, page.CursorPath
+	if len(cursorKey) == 0 {
+		cursorKey = "cursor"
+	}
+	if len(cursorPath) == 0 {
+		cursorPath = "data.cursor"
+	}
+	cursor := res.GetData(cursorPath).String()
+	if count == 0 || len(cursor) == 0 || cursor == param[cursorKey] {
+		return nil
+	}
+	return restPageCopy(param, cursorKey, cursor)
+}
+
+// restPageData 请求一页的结果
+type restPageData struct {
+	page  *JsonResult
+	items []*JsonData
+	err   error
+}
+
+// RestPaginator 分页列表迭代器,按分页方式重复调用 Do 获取全部数据
+//
+//	pages := NewRestPaginator(client, key, param, &RestPageNumber{TotalPath: "data.total"})
+//	defer pages.Close()
+//	for pages.Next(ctx) {
+//		item := pages.Item()
+//	}
+//	err := pages.Err()
+type RestPaginator struct {
+	client   *RestClient
+	key      interface{}
+	strategy RestPageStrategy
+	listPath string
+	prefetch bool
+	maxPages int
+	param    map[string]interface{}
+	pending  chan *restPageData
+	page     *JsonResult
+	items    []*JsonData
+	index    int
+	pages    int
+	done     bool
+	err      error
+}
+
+// NewRestPaginator 创建分页迭代器,列表数据默认从 data.list 获取
+// @param param 基础请求参数,可以为nil,分页参数由 strategy 设置
+func NewRestPaginator(client *RestClient, key interface{}, param map[string]interface{}, strategy RestPageStrategy) *RestPaginator {
+	return &RestPaginator{
+		client:   client,
+		key:      key,
+		strategy: strategy,
+		listPath: "data.list",
+		param:    strategy.First(restPageCopy(param)),
+		index:    -1,
+	}
+}
+
+// WithListPath 设置列表数据的路径
+func (pages *RestPaginator) WithListPath(path string) *RestPaginator {
+	pages.listPath = path
+	return pages
+}
+
+// WithPrefetch 获取到当前页后并发请求下一页
+func (pages *RestPaginator) WithPrefetch() *RestPaginator {
+	pages.prefetch = true
+	return pages
+}
+
+// WithMaxPages 最多请求的页数,默认0不限制
+func (pages *RestPaginator) WithMaxPages(max int) *RestPaginator {
+	pages.maxPages = max
+	return pages
+}
+
+// fetch 请求一页数据
+func (pages *RestPaginator) fetch(ctx context.Context, param map[string]interface{}) chan *restPageData {
+	pc := make(chan *restPageData, 1)
+	ctx = withRestCaller(ctx, 1)
+	go func() {
+		page := (<-pages.client.Do(ctx, pages.key, param)).JsonResult()
+		data := &restPageData{page: page, err: page.Err()}
+		if data.err == nil {
+			list := page.GetData(pages.listPath)
+			if data.err = list.Err(); data.err == nil {
+				data.items = list.Items()
+			}
+		}
+		pc <- data
+	}()
+	return pc
+}
+
+// Next 移动到下一条数据,没有数据、出错或上下文取消时返回false
+func (pages *RestPaginator) Next(ctx context.Context) bool {
+	if pages.err != nil {
+		return false
+	}
+	for pages.index+1 >= len(pages.items) {
+		if pages.done {
+			return false
+		}
+		if pages.pending == nil {
+			pages.pending = pages.fetch(ctx, pages.param)
+		}
+		var data *restPageData
+		select {
+		case <-ctx.Done():
+			pages.err = ctx.Err()
+			return false
+		case data = <-pages.pending:
+		}
+		pages.pending = nil
+		if data.err != nil {
+			pages.err = data.err
+			return false
+		}
+		pages.page, pages.items, pages.index = data.page, data.items, -1
+		pages.pages++
+		if pages.param = pages.strategy.Next(pages.param, data.page, len(data.items)); pages.param == nil ||
+			(pages.maxPages > 0 && pages.pages >= pages.maxPages) {
+			pages.done = true
+		} else if pages.prefetch {
+			pages.pending = pages.fetch(ctx, pages.param)
+		}
+	}
+	pages.index++
+	return true
+}
+
+// Item 当前数据
+func (pages *RestPaginator) Item() *JsonData {
+	if pages.index < 0 || pages.index >= len(pages.items) {
+		return NewJsonDataFromError(NewRestClientError("24", "paginator item not find"))
+	}
+	return pages.items[pages.index]
+}
+
+// Scan 解析当前数据到结构并验证,参数同 JsonResult.GetStruct
+func (pages *RestPaginator) Scan(structPtr interface{}, jsonValid ...*JsonValid) error {
+	item := pages.Item()
+	if err := item.Err(); err != nil {
+		return err
+	}
+	return NewJsonResult(item.Raw, "").GetStruct("", structPtr, jsonValid...)
+}
+
+// Page 当前页的结果
+func (pages *RestPaginator) Page() *JsonResult {
+	return pages.page
+}
+
+// Pages 已获取的页数
+func (pages *RestPaginator) Pages() int {
+	return pages.pages
+}
+
+// Err 迭代中的错误,正常结束时为nil
+func (pages *RestPaginator) Err() error {
+	return pages.err
+}
+
+// Close 停止迭代,预取的下一页会被丢弃
+func (pages *RestPaginator) Close() {
+	pages.done = true
+	pages.items = nil
+	pages.pending = nil
+}
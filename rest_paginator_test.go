@@ -0,0 +1,123 @@
+package rest_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testPageServer(t *testing.T, total int, delay time.Duration, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		time.Sleep(delay)
+		var param map[string]interface{}
+		if err := json.Unmarshal([]byte(r.URL.Query().Get("content")), &param); err != nil {
+			t.Error(err)
+		}
+		start, size := 0, 0
+		if page, ok := param["page"].(float64); ok {
+			size = int(param["page_size"].(float64))
+			start = (int(page) - 1) * size
+		} else if offset, ok := param["offset"].(float64); ok {
+			size = int(param["limit"].(float64))
+			start = int(offset)
+		} else {
+			size = int(param["limit"].(float64))
+			if cursor, ok := param["cursor"].(string); ok {
+				_, _ = fmt.Sscanf(cursor, "c%d", &start)
+			}
+		}
+		list := []map[string]interface{}{}
+		for i := start; i < start+size && i < total; i++ {
+			list = append(list, map[string]interface{}{"id": i + 1, "type": param["type"]})
+		}
+		cursor := ""
+		if start+size < total {
+			cursor = fmt.Sprintf("c%d", start+size)
+		}
+		body, _ := json.Marshal(map[string]interface{}{
+			"result": map[string]string{"code": "200", "state": "ok"},
+			"data":   map[string]interface{}{"list": list, "total": total, "cursor": cursor},
+		})
+		_, _ = w.Write(body)
+	}))
+}
+
+func TestRestPaginator(t *testing.T) {
+	var calls int32
+	server := testPageServer(t, 7, 0, &calls)
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	api := client.NewApi(&testDome1{})
+	for name, strategy := range map[string]RestPageStrategy{
+		"page":   &RestPageNumber{Size: 3, TotalPath: "data.total"},
+		"offset": &RestPageOffset{Limit: 3},
+		"cursor": &RestPageCursor{Limit: 3},
+	} {
+		pages := NewRestPaginator(api, test1, map[string]interface{}{"type": name}, strategy).WithPrefetch()
+		var ids []int64
+		for pages.Next(context.Background()) {
+			var item struct {
+				Id   int    `json:"id" validate:"gt=0"`
+				Type string `json:"type"`
+			}
+			if err := pages.Scan(&item); err != nil {
+				t.Fatal(err)
+			}
+			if item.Type != name || pages.Item().Get("id").Int() != int64(item.Id) {
+				t.Errorf("%s item wrong", name)
+			}
+			ids = append(ids, int64(item.Id))
+		}
+		pages.Close()
+		if pages.Err() != nil || len(ids) != 7 || ids[6] != 7 || pages.Pages() != 3 {
+			t.Errorf("%s paginator wrong:%v %v", name, ids, pages.Err())
+		}
+	}
+
+	pages := NewRestPaginator(api, test1, nil, &RestPageNumber{Size: 3}).WithMaxPages(1)
+	count := 0
+	for pages.Next(context.Background()) {
+		count++
+	}
+	if count != 3 {
+		t.Errorf("max pages wrong:%d", count)
+	}
+}
+
+func TestRestPaginatorCancel(t *testing.T) {
+	var calls int32
+	server := testPageServer(t, 100, 50*time.Millisecond, &calls)
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:   "test111",
+		AppUrl: server.URL,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pages := NewRestPaginator(client.NewApi(&testDome1{}), test1, nil, &RestPageOffset{Limit: 10})
+	defer pages.Close()
+	count := 0
+	for pages.Next(ctx) {
+		count++
+		if count == 10 {
+			cancel()
+		}
+	}
+	if pages.Err() != context.Canceled || count != 10 {
+		t.Errorf("paginator cancel wrong:%d %v", count, pages.Err())
+	}
+	if atomic.LoadInt32(&calls) > 2 {
+		t.Errorf("paginator must stop requesting:%d", calls)
+	}
+}
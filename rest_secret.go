@@ -0,0 +1,233 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RestSecret 接口密钥,轮换时 Next 在 NextFrom 之后生效,生效前后两个密钥由服务端同时认可
+type RestSecret struct {
+	Current  string    `json:"current"`
+	Next     string    `json:"next,omitempty"`
+	NextFrom time.Time `json:"next_from,omitempty"`
+}
+
+// Active 指定时间用于签名的密钥
+func (secret *RestSecret) Active(now time.Time) string {
+	if len(secret.Next) > 0 && !now.Before(secret.NextFrom) {
+		return secret.Next
+	}
+	return secret.Current
+}
+
+// values 全部非空密钥,用于日志脱敏
+func (secret *RestSecret) values() []string {
+	var tmp []string
+	for _, val := range []string{secret.Current, secret.Next} {
+		if len(val) > 0 {
+			tmp = append(tmp, val)
+		}
+	}
+	return tmp
+}
+
+func (secret *RestSecret) String() string {
+	return "RestSecret(******)"
+}
+
+func (secret *RestSecret) GoString() string {
+	return secret.String()
+}
+
+// RestSecretProvider 密钥提供者,签名时按配置名获取密钥
+type RestSecretProvider interface {
+	Secret(ctx context.Context, name string) (*RestSecret, error)
+}
+
+// RestSecretFunc 函数形式的密钥提供者,用于接入vault等外部密钥服务
+type RestSecretFunc func(ctx context.Context, name string) (*RestSecret, error)
+
+func (fn RestSecretFunc) Secret(ctx context.Context, name string) (*RestSecret, error) {
+	return fn(ctx, name)
+}
+
+// RestEnvSecretProvider 从环境变量获取密钥
+// 变量名为 前缀+配置名 的大写,轮换时通过 _NEXT 及 _NEXT_FROM(RFC3339时间) 后缀设置新密钥
+type RestEnvSecretProvider struct {
+	Prefix string
+}
+
+func (provider *RestEnvSecretProvider) Secret(_ context.Context, name string) (*RestSecret, error) {
+	envName := strings.ToUpper(provider.Prefix + name)
+	current, ok := os.LookupEnv(envName)
+	if !ok {
+		return nil, NewRestClientError("12", "secret env is not set:"+envName)
+	}
+	secret := &RestSecret{
+		Current: current,
+		Next:    os.Getenv(envName + "_NEXT"),
+	}
+	if from := os.Getenv(envName + "_NEXT_FROM"); len(from) > 0 {
+		nextFrom, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return nil, NewRestClientError("12", "secret env next from is wrong:"+envName+"_NEXT_FROM")
+		}
+		secret.NextFrom = nextFrom
+	}
+	return secret, nil
+}
+
+// RestFileSecretProvider 从目录下与配置名同名的文件获取密钥
+// 文件第一行为当前密钥,轮换时第二行为新密钥,第三行为新密钥生效时间(RFC3339)
+type RestFileSecretProvider struct {
+	Dir string
+}
+
+func (provider *RestFileSecretProvider) Secret(_ context.Context, name string) (*RestSecret, error) {
+	data, err := ioutil.ReadFile(filepath.Join(provider.Dir, name))
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	secret := &RestSecret{Current: strings.TrimSpace(lines[0])}
+	if len(lines) > 1 {
+		secret.Next = strings.TrimSpace(lines[1])
+	}
+	if len(lines) > 2 {
+		nextFrom, err := time.Parse(time.RFC3339, strings.TrimSpace(lines[2]))
+		if err != nil {
+			return nil, NewRestClientError("12", "secret file next from is wrong:"+name)
+		}
+		secret.NextFrom = nextFrom
+	}
+	return secret, nil
+}
+
+// RestEncryptedSecretProvider 从本地加密文件获取密钥
+// 文件内容为 AES-GCM 加密的 配置名=>RestSecret 的JSON,通过 EncryptRestSecrets 生成
+type RestEncryptedSecretProvider struct {
+	Path string
+	Key  []byte //16、24或32字节的AES密钥
+}
+
+func (provider *RestEncryptedSecretProvider) Secret(_ context.Context, name string) (*RestSecret, error) {
+	data, err := ioutil.ReadFile(provider.Path)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := restSecretGcm(provider.Key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, NewRestClientError("12", "secret store is wrong")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, NewRestClientError("12", "secret store decrypt fail")
+	}
+	var secrets map[string]*RestSecret
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, NewRestClientError("12", "secret store is wrong")
+	}
+	secret, ok := secrets[name]
+	if !ok || secret == nil {
+		return nil, NewRestClientError("12", "secret is not find:"+name)
+	}
+	return secret, nil
+}
+
+// EncryptRestSecrets 加密密钥,结果写入文件后供 RestEncryptedSecretProvider 使用
+func EncryptRestSecrets(key []byte, secrets map[string]*RestSecret) ([]byte, error) {
+	gcm, err := restSecretGcm(key)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+func restSecretGcm(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+type restSecretCache struct {
+	secret   *RestSecret
+	expireAt time.Time
+}
+
+// RestCachedSecretProvider 缓存密钥,避免每次签名都访问密钥来源
+type RestCachedSecretProvider struct {
+	provider RestSecretProvider
+	ttl      time.Duration
+	lock     sync.Mutex
+	cache    map[string]*restSecretCache
+}
+
+// NewRestCachedSecretProvider 创建带缓存的密钥提供者,获取失败时不缓存
+func NewRestCachedSecretProvider(provider RestSecretProvider, ttl time.Duration) *RestCachedSecretProvider {
+	return &RestCachedSecretProvider{
+		provider: provider,
+		ttl:      ttl,
+		cache:    make(map[string]*restSecretCache),
+	}
+}
+
+func (provider *RestCachedSecretProvider) Secret(ctx context.Context, name string) (*RestSecret, error) {
+	provider.lock.Lock()
+	cache, ok := provider.cache[name]
+	provider.lock.Unlock()
+	if ok && time.Now().Before(cache.expireAt) {
+		return cache.secret, nil
+	}
+	secret, err := provider.provider.Secret(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	provider.lock.Lock()
+	provider.cache[name] = &restSecretCache{
+		secret:   secret,
+		expireAt: time.Now().Add(provider.ttl),
+	}
+	provider.lock.Unlock()
+	return secret, nil
+}
+
+// Clear 清除缓存,密钥轮换后调用可立即生效
+func (provider *RestCachedSecretProvider) Clear() {
+	provider.lock.Lock()
+	defer provider.lock.Unlock()
+	provider.cache = make(map[string]*restSecretCache)
+}
+
+// restSecretRedact 替换内容中的密钥,用于回调事件前脱敏
+func restSecretRedact(data []byte, secrets []string) []byte {
+	for _, secret := range secrets {
+		if bytes.Contains(data, []byte(secret)) {
+			data = bytes.ReplaceAll(data, []byte(secret), []byte("******"))
+		}
+	}
+	return data
+}
@@ -0,0 +1,115 @@
+package rest_client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRestSecretActive(t *testing.T) {
+	now := time.Now()
+	secret := &RestSecret{
+		Current:  "s1",
+		Next:     "s2",
+		NextFrom: now.Add(time.Minute),
+	}
+	if secret.Active(now) != "s1" || secret.Active(now.Add(time.Hour)) != "s2" {
+		t.Error("secret active wrong")
+	}
+	if strings.Contains(fmt.Sprintf("%v %#v", secret, secret), "s1") {
+		t.Error("secret must not format")
+	}
+}
+
+func TestRestEnvSecretProvider(t *testing.T) {
+	_ = os.Setenv("TEST_SECRET_TEST111", "s1")
+	_ = os.Setenv("TEST_SECRET_TEST111_NEXT", "s2")
+	_ = os.Setenv("TEST_SECRET_TEST111_NEXT_FROM", "2000-01-01T00:00:00Z")
+	defer func() {
+		_ = os.Unsetenv("TEST_SECRET_TEST111")
+		_ = os.Unsetenv("TEST_SECRET_TEST111_NEXT")
+		_ = os.Unsetenv("TEST_SECRET_TEST111_NEXT_FROM")
+	}()
+	provider := &RestEnvSecretProvider{Prefix: "test_secret_"}
+	secret, err := provider.Secret(context.Background(), "test111")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret.Active(time.Now()) != "s2" {
+		t.Error("env secret wrong")
+	}
+	if _, err := provider.Secret(context.Background(), "not_set"); err == nil {
+		t.Error("env secret must not set")
+	}
+}
+
+func TestRestEncryptedSecretProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rest_secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	key := []byte("0123456789abcdef0123456789abcdef")
+	data, err := EncryptRestSecrets(key, map[string]*RestSecret{
+		"test111": {Current: "s1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "secrets.bin")
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	provider := &RestEncryptedSecretProvider{Path: path, Key: key}
+	secret, err := provider.Secret(context.Background(), "test111")
+	if err != nil || secret.Current != "s1" {
+		t.Error("encrypted secret wrong", err)
+	}
+	provider.Key = []byte("0123456789abcdef0123456789abcdeX")
+	if _, err := provider.Secret(context.Background(), "test111"); err == nil {
+		t.Error("wrong key must fail")
+	}
+}
+
+func TestRestCachedSecretProvider(t *testing.T) {
+	count := 0
+	provider := NewRestCachedSecretProvider(RestSecretFunc(func(_ context.Context, name string) (*RestSecret, error) {
+		count++
+		return &RestSecret{Current: name}, nil
+	}), time.Minute)
+	for i := 0; i < 3; i++ {
+		if secret, _ := provider.Secret(context.Background(), "test111"); secret.Current != "test111" {
+			t.Error("cached secret wrong")
+		}
+	}
+	provider.Clear()
+	_, _ = provider.Secret(context.Background(), "test111")
+	if count != 2 {
+		t.Error("secret cache wrong")
+	}
+}
+
+func TestRestSecretRedact(t *testing.T) {
+	var request []byte
+	event := NewAppRestEvent(func(_ string, url string, _ int, _ map[string][]string, req []byte, _ []byte, _ error) {
+		request = req
+	})
+	read := NewRestRequestReader(strings.NewReader("a=secret111&b=1"), event).WithRedact("secret111")
+	data, _ := ioutil.ReadAll(read)
+	event.ResponseFinish(nil)
+	if string(data) != "a=secret111&b=1" {
+		t.Error("request data must not change")
+	}
+	if strings.Contains(string(request), "secret111") {
+		t.Error("secret must redact in event")
+	}
+	config := &AppRestConfig{Name: "test111", AppSecret: "secret111"}
+	if strings.Contains(fmt.Sprintf("%v %+v %#v", config, config, config), "secret111") {
+		t.Error("config secret must not format")
+	}
+}
@@ -0,0 +1,271 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// SoapVersion SOAP协议版本
+type SoapVersion string
+
+const (
+	Soap11 SoapVersion = "1.1"
+	Soap12 SoapVersion = "1.2"
+)
+
+// SoapBodyPath SOAP返回内容 Body 节点路径,用于 XmlResult
+const SoapBodyPath = "Envelope/Body"
+
+// namespace 版本对应的命名空间
+func (version SoapVersion) namespace() string {
+	if version == Soap12 {
+		return "http://www.w3.org/2003/05/soap-envelope"
+	}
+	return "http://schemas.xmlsoap.org/soap/envelope/"
+}
+
+type soapContent struct {
+	Inner []byte `xml:",innerxml"`
+}
+
+type soapEnvelope struct {
+	XMLName xml.Name     `xml:"soap:Envelope"`
+	Ns      string       `xml:"xmlns:soap,attr"`
+	Header  *soapContent `xml:"soap:Header,omitempty"`
+	Body    soapContent  `xml:"soap:Body"`
+}
+
+// soapInner 内容转为XML, string 及 []byte 视为已编码的XML
+func soapInner(content interface{}) ([]byte, error) {
+	switch tContent := content.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(tContent), nil
+	case []byte:
+		return tContent, nil
+	}
+	return xml.Marshal(content)
+}
+
+// NewSoapEnvelope 生成SOAP请求内容
+// @param header 可以为nil,为 string 或 []byte 时作为已编码的XML
+// @param body 请求参数,为 string 或 []byte 时作为已编码的XML
+func NewSoapEnvelope(version SoapVersion, header interface{}, body interface{}) ([]byte, error) {
+	envelope := soapEnvelope{Ns: version.namespace()}
+	if header != nil {
+		inner, err := soapInner(header)
+		if err != nil {
+			return nil, err
+		}
+		envelope.Header = &soapContent{Inner: inner}
+	}
+	inner, err := soapInner(body)
+	if err != nil {
+		return nil, err
+	}
+	envelope.Body.Inner = inner
+	data, err := xml.Marshal(envelope)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// SoapFaultError SOAP返回的错误
+type SoapFaultError struct {
+	Code   string
+	Reason string
+	Actor  string
+	Detail string
+}
+
+func (err *SoapFaultError) Error() string {
+	return fmt.Sprintf("soap fault:%s [%s]", err.Reason, err.Code)
+}
+
+// NewSoapFaultError 从返回内容中解析SOAP错误,不存在错误时返回nil
+func NewSoapFaultError(res *XmlResult) *SoapFaultError {
+	if res.err != nil {
+		return nil
+	}
+	//不受 basePath 影响
+	faults := res.doc.Find("Envelope/Body/Fault")
+	if len(faults) == 0 {
+		return nil
+	}
+	fault := faults[0]
+	if code := fault.Find("Code/Value"); len(code) > 0 {
+		//SOAP 1.2
+		return &SoapFaultError{
+			Code:   code[0].String(),
+			Reason: xmlNodeString(fault.Find("Reason/Text")),
+			Actor:  xmlNodeString(fault.Find("Role")),
+			Detail: xmlNodeRaw(fault.Find("Detail")),
+		}
+	}
+	return &SoapFaultError{
+		Code:   xmlNodeString(fault.Find("faultcode")),
+		Reason: xmlNodeString(fault.Find("faultstring")),
+		Actor:  xmlNodeString(fault.Find("faultactor")),
+		Detail: xmlNodeRaw(fault.Find("detail")),
+	}
+}
+
+func xmlNodeString(nodes []*XmlNode) string {
+	if len(nodes) == 0 {
+		return ""
+	}
+	return nodes[0].String()
+}
+
+func xmlNodeRaw(nodes []*XmlNode) string {
+	if len(nodes) == 0 {
+		return ""
+	}
+	return string(nodes[0].raw)
+}
+
+// SoapRestConfig SOAP服务配置
+type SoapRestConfig struct {
+	Name        string
+	Url         string
+	EventCreate func(ctx context.Context) RestEvent
+	MaxBodySize int64                       //返回内容最大字节数,默认0不限制
+	Builds      map[string]*RestBuildConfig //按接口KEY覆盖接口配置,可以为nil
+}
+
+func (clf *SoapRestConfig) GetName() string {
+	return clf.Name
+}
+
+// BaseUrl 服务地址
+func (clf *SoapRestConfig) BaseUrl() string {
+	return clf.Url
+}
+
+// GetMaxBodySize 返回内容最大字节数
+func (clf *SoapRestConfig) GetMaxBodySize() int64 {
+	return clf.MaxBodySize
+}
+
+// GetBuilds 按接口KEY的覆盖配置
+func (clf *SoapRestConfig) GetBuilds() map[string]*RestBuildConfig {
+	return clf.Builds
+}
+
+// Valid 校验配置
+func (clf *SoapRestConfig) Valid() error {
+	if len(clf.Name) == 0 {
+		return NewRestConfigError("name", "config name is empty")
+	}
+	if _, err := url.ParseRequestURI(clf.Url); err != nil {
+		return NewRestConfigError(clf.Name+".url", "url is wrong:"+clf.Url)
+	}
+	return nil
+}
+
+// NewEvent 创建请求事件,未配置 EventCreate 时返回默认事件
+func (clf *SoapRestConfig) NewEvent(ctx context.Context) RestEvent {
+	if clf.EventCreate != nil {
+		return clf.EventCreate(ctx)
+	}
+	return &RestEventNoop{}
+}
+
+// SoapRestBuild SOAP接口配置,请求参数作为 Body 内容,通过 RestResult.XmlResult 获取结果
+type SoapRestBuild struct {
+	Timeout     time.Duration //指定接口超时时间,默认0,跟全局一致
+	Path        string        //接口路径
+	Action      string        //SOAPAction
+	Version     SoapVersion   //默认 Soap11
+	Header      interface{}   //SOAP Header 内容,可以为nil
+	Decode      *RestDecode   //返回内容解码配置
+	MaxBodySize int64         //返回内容最大字节数,默认0使用服务配置
+}
+
+// BuildInfo 接口信息
+func (clt *SoapRestBuild) BuildInfo() *RestEndpoint {
+	return &RestEndpoint{
+		HttpMethod: http.MethodPost,
+		Path:       clt.Path,
+		Method:     clt.Action,
+		Timeout:    clt.Timeout,
+	}
+}
+
+// ResponseDecode 返回内容解码配置
+func (clt *SoapRestBuild) ResponseDecode() *RestDecode {
+	return clt.Decode
+}
+
+// CheckXmlResult SOAP Fault 转为 SoapFaultError
+func (clt *SoapRestBuild) CheckXmlResult(res *XmlResult) error {
+	if fault := NewSoapFaultError(res); fault != nil {
+		return fault
+	}
+	return nil
+}
+
+// BuildRequest 执行请求
+func (clt *SoapRestBuild) BuildRequest(ctx context.Context, client *RestClient, key int, param interface{}, callerInfo *RestCallerInfo) *RestResult {
+	return clt.BuildKeyRequest(ctx, client, restIntKey(key), param, callerInfo)
+}
+
+// BuildKeyRequest 按接口KEY执行请求
+func (clt *SoapRestBuild) BuildKeyRequest(ctx context.Context, client *RestClient, key RestKey, param interface{}, _ *RestCallerInfo) *RestResult {
+	tConfig, err := client.GetConfig(ctx)
+	if err != nil {
+		return NewRestResultFromError(err, &RestEventNoop{})
+	}
+	config, ok := tConfig.(*SoapRestConfig)
+	if !ok {
+		return NewRestResultFromError(NewRestClientError("11", "build config is wrong"), &RestEventNoop{})
+	}
+
+	event := restBuildEvent(ctx, config, key)
+
+	version := clt.Version
+	if len(version) == 0 {
+		version = Soap11
+	}
+	body, err := NewSoapEnvelope(version, clt.Header, param)
+	if err != nil {
+		return NewRestResultFromError(err, event)
+	}
+	apiUrl := config.Url + clt.Path
+	event.RequestStart(http.MethodPost, apiUrl)
+	send := newRestTimeoutSend(ctx)
+	req, err := http.NewRequestWithContext(send.ctx, http.MethodPost, apiUrl, NewRestRequestReader(bytes.NewReader(body), event))
+	if err != nil {
+		send.cancel()
+		return NewRestResultFromError(err, event)
+	}
+	req.ContentLength = int64(len(body))
+	if version == Soap12 {
+		contentType := "application/soap+xml; charset=utf-8"
+		if len(clt.Action) > 0 {
+			contentType += "; action=\"" + clt.Action + "\""
+		}
+		req.Header.Set("Content-Type", contentType)
+	} else {
+		req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+		req.Header.Set("SOAPAction", "\""+clt.Action+"\"")
+	}
+
+	timeout, maxBodySize := restBuildLimit(config, key, clt.Timeout, clt.MaxBodySize)
+	send.start(timeout)
+	httpClient := &http.Client{
+		Transport: client.headerTransport(client.GetConfigTransport(config.GetName()), timeout),
+	}
+	res, err := send.finish(httpClient.Do(req))
+	if err != nil {
+		return NewRestResultFromError(err, event)
+	}
+	return NewRestResult(clt, res, event).WithMaxBodySize(maxBodySize)
+}
@@ -0,0 +1,110 @@
+package rest_client
+
+import (
+	"context"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testSoapApi struct{}
+
+const (
+	testSoapPrice = iota
+	testSoapFault = iota
+)
+
+func (res *testSoapApi) ConfigBuilds(_ context.Context) (map[int]RestBuild, error) {
+	return map[int]RestBuild{
+		testSoapPrice: &SoapRestBuild{
+			Path:   "/price",
+			Action: "urn:GetPrice",
+		},
+		testSoapFault: &SoapRestBuild{
+			Path:    "/fault",
+			Action:  "urn:GetPrice",
+			Version: Soap12,
+		},
+	}, nil
+}
+
+func (res *testSoapApi) ConfigName(_ context.Context) (string, error) {
+	return "soap", nil
+}
+
+type testSoapPriceRequest struct {
+	XMLName xml.Name `xml:"urn:test GetPrice"`
+	Sku     string   `xml:"Sku"`
+}
+
+func TestSoapRestBuild(t *testing.T) {
+	var request string
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		request = string(data)
+		header = r.Header
+		if r.URL.Path == "/fault" {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><env:Fault>
+<env:Code><env:Value>env:Sender</env:Value></env:Code>
+<env:Reason><env:Text xml:lang="en">sku not find</env:Text></env:Reason>
+</env:Fault></env:Body></env:Envelope>`))
+			return
+		}
+		_, _ = w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>
+<GetPriceResponse xmlns="urn:test"><Price>12.5</Price></GetPriceResponse>
+</soap:Body></soap:Envelope>`))
+	}))
+	defer server.Close()
+	client := NewRestClientManager()
+	client.SetRestConfig(&SoapRestConfig{
+		Name: "soap",
+		Url:  server.URL,
+	})
+	api := client.NewApi(&testSoapApi{})
+	res := (<-api.Do(context.Background(), testSoapPrice, &testSoapPriceRequest{Sku: "a1"})).XmlResult(SoapBodyPath)
+	if err := res.Err(); err != nil {
+		t.Fatal(err)
+	}
+	var price struct {
+		Price float64 `xml:"Price" validate:"gt=0"`
+	}
+	if err := res.GetStruct("GetPriceResponse", &price); err != nil || price.Price != 12.5 {
+		t.Errorf("price wrong:%v", err)
+	}
+	if !strings.Contains(request, `<soap:Body><GetPrice xmlns="urn:test"><Sku>a1</Sku></GetPrice></soap:Body>`) {
+		t.Errorf("request wrong:%s", request)
+	}
+	if header.Get("SOAPAction") != `"urn:GetPrice"` || !strings.HasPrefix(header.Get("Content-Type"), "text/xml") {
+		t.Error("soap 1.1 header wrong")
+	}
+
+	err := (<-api.Do(context.Background(), testSoapFault, "<GetPrice/>")).XmlResult(SoapBodyPath).Err()
+	fault, ok := err.(*SoapFaultError)
+	if !ok || fault.Code != "env:Sender" || fault.Reason != "sku not find" {
+		t.Errorf("fault wrong:%v", err)
+	}
+	if !strings.Contains(header.Get("Content-Type"), `action="urn:GetPrice"`) {
+		t.Error("soap 1.2 header wrong")
+	}
+	if !strings.Contains(request, "http://www.w3.org/2003/05/soap-envelope") {
+		t.Error("soap 1.2 namespace wrong")
+	}
+}
+
+func TestSoapFault11(t *testing.T) {
+	res := NewXmlResult(`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><s:Fault>
+<faultcode>s:Server</faultcode><faultstring>system error</faultstring><detail><code>500</code></detail>
+</s:Fault></s:Body></s:Envelope>`, "")
+	fault := NewSoapFaultError(res)
+	if fault == nil || fault.Code != "s:Server" || fault.Reason != "system error" || fault.Detail != "<detail><code>500</code></detail>" {
+		t.Errorf("fault wrong:%v", fault)
+	}
+	if NewSoapFaultError(NewXmlResult("<a/>", "")) != nil {
+		t.Error("no fault must nil")
+	}
+}
@@ -1,6 +1,8 @@
 package rest_client
 
 import (
+	"context"
+	"path"
 	"runtime"
 	"strconv"
 	"strings"
@@ -52,3 +54,47 @@ func callerFileInfo(lastSkipMatch string, startLoop int, maxLoop int) *RestCalle
 		FuncName: funcName,
 	}
 }
+
+// restPackageDir 本包源码所在目录,用于跳过包内的调用
+var restPackageDir = func() string {
+	_, fileName, _, _ := runtime.Caller(0)
+	return path.Dir(fileName)
+}()
+
+// restCallerInfo 获取本包外的第一个调用方,包内的测试文件视为调用方
+// @param skip 跳过的调用层数,0为 restCallerInfo 的调用方
+func restCallerInfo(skip int) *RestCallerInfo {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(skip+2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if len(frame.File) > 0 && (path.Dir(frame.File) != restPackageDir || strings.HasSuffix(frame.File, "_test.go")) {
+			return &RestCallerInfo{
+				FileName: frame.File,
+				FileLine: strconv.Itoa(frame.Line),
+				FuncName: frame.Function,
+			}
+		}
+		if !more {
+			return &RestCallerInfo{}
+		}
+	}
+}
+
+type restCallerKey struct{}
+
+// withRestCaller 在上下文中记录调用方,包内在新协程中调用 Do 时使用,已记录时不覆盖
+func withRestCaller(ctx context.Context, skip int) context.Context {
+	if _, ok := ctx.Value(restCallerKey{}).(*RestCallerInfo); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, restCallerKey{}, restCallerInfo(skip+1))
+}
+
+// restCallerFrom 上下文中记录的调用方,未记录时获取当前调用方
+func restCallerFrom(ctx context.Context, skip int) *RestCallerInfo {
+	if caller, ok := ctx.Value(restCallerKey{}).(*RestCallerInfo); ok {
+		return caller
+	}
+	return restCallerInfo(skip + 1)
+}
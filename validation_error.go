@@ -0,0 +1,56 @@
+package rest_client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationFieldError 单个字段的校验错误
+type ValidationFieldError struct {
+	Path    string      `json:"path"`            //完整的JSON路径,如 data.items[3].price
+	Field   string      `json:"field,omitempty"` //结构字段名
+	Tag     string      `json:"tag"`             //校验TAG
+	Param   string      `json:"param,omitempty"` //TAG参数
+	Value   interface{} `json:"value,omitempty"` //实际值,通过 JsonValid.WithRedact 隐藏
+	Message string      `json:"message"`         //错误信息,设置语言时为翻译后的信息
+}
+
+func (err *ValidationFieldError) Error() string {
+	return fmt.Sprintf("path:%s field:%s tag:%s error:%s ", err.Path, err.Field, err.Tag, err.Message)
+}
+
+// ValidationError 校验错误,包含全部校验失败的字段,可直接序列化到接口返回
+type ValidationError struct {
+	Fields []*ValidationFieldError `json:"fields"`
+}
+
+func (err *ValidationError) Error() string {
+	msg := make([]string, 0, len(err.Fields))
+	for _, field := range err.Fields {
+		msg = append(msg, field.Error())
+	}
+	return strings.Join(msg, ";")
+}
+
+// Unwrap 兼容原有的 RestClientError 错误码
+func (err *ValidationError) Unwrap() error {
+	return NewRestClientError("20", err.Error())
+}
+
+// Field 获取指定JSON路径的校验错误,不存在时返回nil
+func (err *ValidationError) Field(path string) *ValidationFieldError {
+	for _, field := range err.Fields {
+		if field.Path == path {
+			return field
+		}
+	}
+	return nil
+}
+
+// orNil 无校验失败字段时返回nil
+func (err *ValidationError) orNil() error {
+	if len(err.Fields) == 0 {
+		return nil
+	}
+	return err
+}
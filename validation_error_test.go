@@ -0,0 +1,82 @@
+package rest_client
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/tidwall/gjson"
+	"strings"
+	"testing"
+)
+
+func TestValidationError(t *testing.T) {
+	body := `{"data":{"id":0,"items":[{"sku":"a","price":1},{"price":0},{"sku":"c","price":-1}]}}`
+	err := NewJsonResult(body, "").GetStruct("data", &tmpOrder{})
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("validation error type wrong:%v", err)
+	}
+	if len(vErr.Fields) != 4 {
+		t.Fatalf("validation fields wrong:%v", err)
+	}
+	for _, path := range []string{"data.items[1].sku", "data.items[1].price", "data.items[2].price", "data.id"} {
+		if vErr.Field(path) == nil {
+			t.Errorf("validation path not find:%s", path)
+		}
+	}
+	price := vErr.Field("data.items[2].price")
+	if price.Tag != "gt" || price.Param != "0" || price.Value != float64(-1) || price.Field != "Price" {
+		t.Errorf("validation field wrong:%+v", price)
+	}
+	var rErr *RestClientError
+	if !errors.As(err, &rErr) || rErr.Code != "20" {
+		t.Errorf("validation error code wrong:%v", err)
+	}
+
+	if msg := price.Message; msg != "Field validation for 'price' failed on the 'gt' tag" {
+		t.Errorf("validation walk message wrong:%s", msg)
+	}
+	user := struct {
+		Age int `json:"age" validate:"gte=18"`
+	}{Age: 10}
+	var vStructErr *ValidationError
+	if structErr := validStruct(newJsonValidate(), &user, nil); !errors.As(structErr, &vStructErr) ||
+		vStructErr.Field("age") == nil || vStructErr.Field("age").Message != "Field validation for 'age' failed on the 'gte' tag" {
+		t.Errorf("validation struct message wrong:%v", structErr)
+	}
+	varErr := NewJsonResult(`{"a":{"age":200}}`, "a").GetData(&JsonKey{Path: "age", Tag: "lte=130", ToType: func(res *gjson.Result) interface{} { return res.Int() }}).Err()
+	var vVarErr *ValidationError
+	if !errors.As(varErr, &vVarErr) || vVarErr.Field("a.age") == nil || vVarErr.Field("a.age").Message != "Field validation for 'age' failed on the 'lte' tag" {
+		t.Errorf("validation var message wrong:%v", varErr)
+	}
+
+	data, _ := json.Marshal(vErr)
+	if !strings.Contains(string(data), `"path":"data.items[2].price","field":"Price","tag":"gt","param":"0","value":-1`) {
+		t.Errorf("validation json wrong:%s", data)
+	}
+}
+
+func TestValidationErrorRedact(t *testing.T) {
+	type account struct {
+		Email    string `json:"email" validate:"email"`
+		Password string `json:"password" validate:"min=8"`
+	}
+	body := `{"email":"bad","password":"123"}`
+	err := NewJsonResult(body, "").GetStruct("", &account{}, NewJsonValid(nil).WithRedact("password"))
+	var vErr *ValidationError
+	if !errors.As(err, &vErr) || len(vErr.Fields) != 2 {
+		t.Fatalf("validation error wrong:%v", err)
+	}
+	if vErr.Field("email").Value != "bad" || vErr.Field("password").Value != "******" {
+		t.Error("validation redact wrong")
+	}
+	err = NewJsonResult(body, "").GetStruct("", &account{}, NewJsonValid(nil).WithRedact())
+	if !errors.As(err, &vErr) || vErr.Field("email").Value != "******" {
+		t.Error("validation redact all wrong")
+	}
+
+	jv, _ := NewJsonValid(nil).WithLocale("zh")
+	err = NewJsonResult(`{"a":{"age":200}}`, "a").GetData(&JsonKey{Path: "age", Tag: "lte=130", ToType: func(res *gjson.Result) interface{} { return res.Int() }, JsonValid: jv}).Err()
+	if !errors.As(err, &vErr) || vErr.Field("a.age") == nil || !strings.HasPrefix(vErr.Field("a.age").Message, "age") {
+		t.Errorf("validation var error wrong:%v", err)
+	}
+}
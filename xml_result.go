@@ -0,0 +1,241 @@
+package rest_client
+
+import (
+	"bytes"
+	"encoding/xml"
+	"github.com/go-playground/validator/v10"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// XmlNode XML节点
+type XmlNode struct {
+	Name     xml.Name
+	Attr     []xml.Attr
+	Text     string //节点直接包含的文本
+	Children []*XmlNode
+	raw      []byte
+}
+
+// String 节点文本,去除首尾空白
+func (node *XmlNode) String() string {
+	if node == nil {
+		return ""
+	}
+	return strings.TrimSpace(node.Text)
+}
+
+// AttrValue 获取属性值,忽略命名空间
+func (node *XmlNode) AttrValue(name string) string {
+	if node == nil {
+		return ""
+	}
+	for _, attr := range node.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// Find 按路径查找节点,路径格式类似XPath:
+// a/b/c 子节点, //c 任意层级节点, * 任意节点名, c[2] 同级第2个节点(从1开始), @name 属性
+// 节点名忽略命名空间前缀
+func (node *XmlNode) Find(path string) []*XmlNode {
+	if node == nil {
+		return nil
+	}
+	nodes := []*XmlNode{node}
+	descendant := false
+	for i, seg := range strings.Split(path, "/") {
+		if len(seg) == 0 {
+			if i > 0 {
+				descendant = true
+			}
+			continue
+		}
+		name, index := xmlPathSegment(seg)
+		var next []*XmlNode
+		for _, tmp := range nodes {
+			if strings.HasPrefix(name, "@") {
+				for _, attr := range tmp.Attr {
+					if attr.Name.Local == name[1:] {
+						next = append(next, &XmlNode{Name: attr.Name, Text: attr.Value})
+					}
+				}
+				continue
+			}
+			var matched []*XmlNode
+			for _, child := range tmp.children(descendant) {
+				if name == "*" || child.Name.Local == name {
+					matched = append(matched, child)
+				}
+			}
+			if index > 0 {
+				if index <= len(matched) {
+					next = append(next, matched[index-1])
+				}
+			} else {
+				next = append(next, matched...)
+			}
+		}
+		nodes = next
+		descendant = false
+	}
+	return nodes
+}
+
+// children 子节点,descendant 为 true 时返回全部层级的子节点
+func (node *XmlNode) children(descendant bool) []*XmlNode {
+	if !descendant {
+		return node.Children
+	}
+	var tmp []*XmlNode
+	for _, child := range node.Children {
+		tmp = append(tmp, child)
+		tmp = append(tmp, child.children(true)...)
+	}
+	return tmp
+}
+
+// xmlPathSegment 解析路径中的节点名及序号
+func xmlPathSegment(seg string) (string, int) {
+	start := strings.Index(seg, "[")
+	if start == -1 || !strings.HasSuffix(seg, "]") {
+		return seg, 0
+	}
+	index, err := strconv.Atoi(seg[start+1 : len(seg)-1])
+	if err != nil {
+		return seg, 0
+	}
+	return seg[0:start], index
+}
+
+// parseXmlNode 解析XML为节点树,返回文档节点,根元素为其子节点
+// 内容需为UTF-8,字符集转换由 RestResult 按 Content-Type 或 RestDecode 完成
+func parseXmlNode(body []byte) (*XmlNode, error) {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	doc := &XmlNode{raw: body}
+	stack := []*XmlNode{doc}
+	var starts []int64
+	for {
+		offset := dec.InputOffset()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch tTok := tok.(type) {
+		case xml.StartElement:
+			node := &XmlNode{Name: tTok.Name, Attr: tTok.Attr}
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, node)
+			stack = append(stack, node)
+			starts = append(starts, offset)
+		case xml.EndElement:
+			stack[len(stack)-1].raw = body[starts[len(starts)-1]:dec.InputOffset()]
+			stack = stack[0 : len(stack)-1]
+			starts = starts[0 : len(starts)-1]
+		case xml.CharData:
+			stack[len(stack)-1].Text += string(tTok)
+		}
+	}
+	if len(doc.Children) == 0 {
+		return nil, NewRestClientError("21", "xml root element not find")
+	}
+	return doc, nil
+}
+
+// XmlResult XML结果集
+type XmlResult struct {
+	valid    *validator.Validate
+	basePath string
+	body     string
+	doc      *XmlNode
+	err      error
+}
+
+// NewXmlResult 解析一个XML字符串为XML结果
+// @param xmlBody XML内容
+// @param basePath 从某个节点获取,传入空字符串表示根节点获取
+func NewXmlResult(xmlBody string, basePath string) *XmlResult {
+	doc, err := parseXmlNode([]byte(xmlBody))
+	if err != nil {
+		return NewXmlResultFromError(err)
+	}
+	return &XmlResult{body: xmlBody, basePath: basePath, doc: doc}
+}
+
+// NewXmlResultFromError 创建一个错误XML结果
+func NewXmlResultFromError(err error) *XmlResult {
+	return &XmlResult{err: err}
+}
+
+// Err XML结果是否错误
+func (res *XmlResult) Err() error {
+	return res.err
+}
+
+// Body XML原始内容
+func (res *XmlResult) Body() string {
+	return res.body
+}
+
+// path 拼接基础路径
+func (res *XmlResult) path(path string) string {
+	if len(res.basePath) == 0 {
+		return path
+	}
+	if len(path) == 0 {
+		return res.basePath
+	}
+	return strings.TrimSuffix(res.basePath, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// GetNodes 获取路径匹配的全部节点,路径格式参见 XmlNode.Find
+func (res *XmlResult) GetNodes(path string) []*XmlNode {
+	if res.err != nil {
+		return nil
+	}
+	path = res.path(path)
+	if len(path) == 0 {
+		return res.doc.Children
+	}
+	return res.doc.Find(path)
+}
+
+// GetNode 获取路径匹配的第一个节点,不存在时返回nil
+func (res *XmlResult) GetNode(path string) *XmlNode {
+	nodes := res.GetNodes(path)
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes[0]
+}
+
+// GetString 获取路径匹配的第一个节点文本
+func (res *XmlResult) GetString(path string) string {
+	return res.GetNode(path).String()
+}
+
+// GetStruct 从XML中解析出结构并验证,节点不存在时仅验证
+func (res *XmlResult) GetStruct(path string, structPtr interface{}, jsonValid ...*JsonValid) error {
+	if res.err != nil {
+		return res.err
+	}
+	if node := res.GetNode(path); node != nil {
+		if err := xml.Unmarshal(node.raw, structPtr); err != nil {
+			return err
+		}
+	}
+	if res.valid == nil {
+		res.valid = newJsonValidate()
+	}
+	return validStruct(res.valid, structPtr, jsonValid)
+}
@@ -0,0 +1,65 @@
+package rest_client
+
+import (
+	"testing"
+)
+
+func TestXmlResult(t *testing.T) {
+	body := `<?xml version="1.0" encoding="GBK"?>
+<order id="100" xmlns:x="urn:x">
+	<x:item sku="a1"><name>book</name><price>10.5</price></x:item>
+	<x:item sku="a2"><name>pen</name><price>2</price></x:item>
+	<remark/>
+</order>`
+	res := NewXmlResult(body, "")
+	if err := res.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if res.GetString("order/@id") != "100" {
+		t.Error("attr path wrong")
+	}
+	if len(res.GetNodes("order/item")) != 2 {
+		t.Error("children path wrong")
+	}
+	if res.GetString("order/item[2]/name") != "pen" || res.GetNode("order/item[2]").AttrValue("sku") != "a2" {
+		t.Error("index path wrong")
+	}
+	if res.GetString("//price") != "10.5" || len(res.GetNodes("//name")) != 2 {
+		t.Error("descendant path wrong")
+	}
+	if res.GetNode("order/item[3]") != nil || res.GetString("order/none") != "" {
+		t.Error("not exists path must empty")
+	}
+	if len(NewXmlResult(body, "order").GetNodes("*")) != 3 {
+		t.Error("base path wrong")
+	}
+
+	type item struct {
+		Sku   string  `xml:"sku,attr" validate:"required"`
+		Name  string  `xml:"name" validate:"required"`
+		Price float64 `xml:"price" validate:"gt=5"`
+	}
+	var tmp item
+	if err := res.GetStruct("order/item[1]", &tmp); err != nil {
+		t.Fatal(err)
+	}
+	if tmp.Sku != "a1" || tmp.Name != "book" || tmp.Price != 10.5 {
+		t.Error("struct decode wrong")
+	}
+	if err := res.GetStruct("order/item[2]", &item{}); err == nil {
+		t.Error("struct valid must fail")
+	}
+	var items struct {
+		Items []item `xml:"item"`
+	}
+	if err := res.GetStruct("order", &items); err != nil || len(items.Items) != 2 {
+		t.Error("slice decode wrong")
+	}
+	var id string
+	if err := res.GetStruct("order/@id", &id); err == nil {
+		t.Error("attr can not decode")
+	}
+	if NewXmlResult("<a>", "").Err() == nil {
+		t.Error("xml wrong must fail")
+	}
+}